@@ -0,0 +1,284 @@
+package gotry
+
+import (
+	"sync"
+	"time"
+)
+
+// AttemptInfo describes an attempt to the ...Ex events, so every event learns the same things.
+type AttemptInfo struct {
+	// Attempt is 1 for the first attempt, 2 for the first retry and so on.
+	Attempt   int
+	StartTime time.Time
+	// Elapsed is how long the attempt ran.
+	Elapsed time.Duration
+	// TotalElapsed is how long the execution has run since its first attempt started.
+	TotalElapsed time.Duration
+	// NextDelay is the delay before the next retry, only OnRetryEx learns it.
+	NextDelay time.Duration
+	LastError error
+	// Succeeded tells whether the attempt, or the execution for OnExecutionDone, succeeded.
+	Succeeded bool
+	// PanicValue and Stack are set when the attempt panicked.
+	PanicValue   interface{}
+	Stack        []byte
+	PolicyName   string
+	OperationKey string
+}
+
+type OnAttemptEvent func(info AttemptInfo)
+
+// WithName names the policy in AttemptInfo.
+func (p policy) WithName(name string) Policy {
+	p.name = name
+	return &p
+}
+
+// WithOperationKey tells in AttemptInfo which operation the policy guards, derive a policy per operation
+// to share its configuration.
+func (p policy) WithOperationKey(operationKey string) Policy {
+	p.operationKey = operationKey
+	return &p
+}
+
+// WithOnRetryEx fires right before waiting for a retry, with the failed attempt and the delay to wait.
+// Unlike OnFuncRetry it also fires for panicked attempts, and never fires when no retry follows.
+func (p policy) WithOnRetryEx(onRetry OnAttemptEvent) Policy {
+	p.onRetryEx = chainAttemptEvent(p.onRetryEx, onRetry)
+	return &p
+}
+
+func (p policy) WithOnPanicEx(onPanic OnAttemptEvent) Policy {
+	p.onPanicEx = chainAttemptEvent(p.onPanicEx, onPanic)
+	return &p
+}
+
+// WithOnTimeoutEx fires once overall timeout expires, with the attempt running or last run by then.
+func (p policy) WithOnTimeoutEx(onTimeout OnAttemptEvent) Policy {
+	p.onTimeoutEx = chainAttemptEvent(p.onTimeoutEx, onTimeout)
+	return &p
+}
+
+func (p policy) WithOnAttemptTimeoutEx(onAttemptTimeout OnAttemptEvent) Policy {
+	p.onAttemptTimeoutEx = chainAttemptEvent(p.onAttemptTimeoutEx, onAttemptTimeout)
+	return &p
+}
+
+// WithOnAttemptDone fires after every attempt, whether it succeeded, failed, panicked or timed out.
+func (p policy) WithOnAttemptDone(onAttemptDone OnAttemptEvent) Policy {
+	p.onAttemptDone = chainAttemptEvent(p.onAttemptDone, onAttemptDone)
+	return &p
+}
+
+// WithOnExecutionDone fires once per TryFunc/TryMethod before fallback, with the last attempt, the error
+// returned by the policy and the time the whole execution took. A panic escaping the policy is reported
+// as PanicError.
+func (p policy) WithOnExecutionDone(onExecutionDone OnAttemptEvent) Policy {
+	p.onExecutionDone = chainAttemptEvent(p.onExecutionDone, onExecutionDone)
+	return &p
+}
+
+func chainAttemptEvent(originEvent OnAttemptEvent, event OnAttemptEvent) OnAttemptEvent {
+	if originEvent == nil {
+		return event
+	}
+	return func(info AttemptInfo) {
+		originEvent(info)
+		event(info)
+	}
+}
+
+func (p *policy) startAttempt(retried int, executionStart time.Time) *AttemptInfo {
+	now := p.clock.Now()
+	attempt := &AttemptInfo{
+		Attempt:      retried + 1,
+		StartTime:    now,
+		TotalElapsed: now.Sub(executionStart),
+		PolicyName:   p.name,
+		OperationKey: p.operationKey,
+	}
+	p.progress.update(*attempt, true)
+	return attempt
+}
+
+func (p *policy) finishAttempt(attempt *AttemptInfo, funcReturn FuncReturn) {
+	elapsed := p.clock.Now().Sub(attempt.StartTime)
+	// an attempt timeout finishes the attempt before the attempt loop does, count its time once
+	attempt.TotalElapsed += elapsed - attempt.Elapsed
+	attempt.Elapsed = elapsed
+	attempt.LastError = funcReturn.Err
+	attempt.Succeeded = success(funcReturn)
+	p.progress.update(*attempt, false)
+}
+
+func (p *policy) finishPanickedAttempt(attempt *AttemptInfo, panicError *PanicError) {
+	attempt.PanicValue = panicError.Value
+	attempt.Stack = panicError.Stack
+	p.finishAttempt(attempt, FuncReturn{Valid: false, Err: panicError})
+}
+
+// attemptProgress lets overall timeout, which gives up on the goroutine running the attempts, tell
+// which attempt it gave up on, and keeps every finished attempt for RetryError.
+type attemptProgress struct {
+	// events is held while the attempt loop fires events, so none fires once timedOut has returned
+	events         sync.Mutex
+	mutex          sync.Mutex
+	clock          Clock
+	executionStart time.Time
+	last           AttemptInfo
+	running        bool
+	attempts       []AttemptInfo
+	// abandoned stops recording attempts and firing events of the goroutine overall timeout gave up on,
+	// it is written holding both events and mutex
+	abandoned bool
+}
+
+func newAttemptProgress(clock Clock) *attemptProgress {
+	return &attemptProgress{clock: clock, executionStart: clock.Now()}
+}
+
+func (a *attemptProgress) update(attempt AttemptInfo, running bool) {
+	if a == nil {
+		return
+	}
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.abandoned {
+		return
+	}
+	a.last = attempt
+	a.running = running
+	if !running {
+		a.record(attempt)
+	}
+}
+
+// record keeps the attempt, an attempt finished twice (e.g. timed out, then returned) is kept once.
+func (a *attemptProgress) record(attempt AttemptInfo) {
+	if n := len(a.attempts); n > 0 && a.attempts[n-1].Attempt == attempt.Attempt {
+		a.attempts[n-1] = attempt
+		return
+	}
+	a.attempts = append(a.attempts, attempt)
+}
+
+// fire runs the events of the attempt loop, unless overall timeout has given up on the loop: the
+// caller has got TimeoutError by then and only OnAbandoned may still report on the loop.
+func (a *attemptProgress) fire(events func()) {
+	if a == nil {
+		events()
+		return
+	}
+	a.events.Lock()
+	defer a.events.Unlock()
+	if !a.abandoned {
+		events()
+	}
+}
+
+func (a *attemptProgress) timedOut() AttemptInfo {
+	a.events.Lock()
+	defer a.events.Unlock()
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	now := a.clock.Now()
+	info := a.last
+	if a.running {
+		info.Elapsed = now.Sub(info.StartTime)
+	}
+	info.TotalElapsed = now.Sub(a.executionStart)
+	info.LastError = TimeoutError
+	if a.running {
+		a.record(info)
+	}
+	a.abandoned = true
+	return info
+}
+
+// executionDone describes the execution by its last attempt.
+func (a *attemptProgress) executionDone(funcReturn FuncReturn) AttemptInfo {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	info := a.last
+	info.TotalElapsed = a.clock.Now().Sub(a.executionStart)
+	info.LastError = funcReturn.Err
+	info.Succeeded = success(funcReturn)
+	if panicError, ok := funcReturn.Err.(*PanicError); ok {
+		info.PanicValue, info.Stack = panicError.Value, panicError.Stack
+	}
+	return info
+}
+
+func (a *attemptProgress) finishedAttempts() []AttemptInfo {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return append([]AttemptInfo(nil), a.attempts...)
+}
+
+// trackingExecution gives execution a copy of the policy recording its attempts, when RetryError or
+// OnExecutionDone need them, and reports how execution ended.
+func (p *policy) trackingExecution(execution func(*policy) FuncReturn) Func {
+	return func() (funcReturn FuncReturn) {
+		if !p.aggregateErrors && p.onExecutionDone == nil && p.logger == nil {
+			return execution(p)
+		}
+		tracked := *p
+		tracked.progress = newAttemptProgress(p.clock)
+		panicked := true
+		defer func() {
+			if panicked {
+				panicErr := recover()
+				p.notifyOnExecutionDone(tracked.progress.executionDone(FuncReturn{Valid: false, Err: newPanicError(panicErr)}))
+				panic(panicErr)
+			}
+		}()
+		funcReturn = execution(&tracked)
+		panicked = false
+		if p.aggregateErrors && funcReturn.Err != nil {
+			funcReturn.Err = &RetryError{Attempts: tracked.progress.finishedAttempts(), LastError: funcReturn.Err}
+		}
+		p.notifyOnExecutionDone(tracked.progress.executionDone(funcReturn))
+		return
+	}
+}
+
+func (p *policy) notifyOnAttemptDone(attempt AttemptInfo) {
+	if p.onAttemptDone != nil {
+		p.onAttemptDone(attempt)
+	}
+}
+
+func (p *policy) notifyOnExecutionDone(attempt AttemptInfo) {
+	p.logExecutionDone(attempt)
+	if p.onExecutionDone != nil {
+		p.onExecutionDone(attempt)
+	}
+}
+
+func (p *policy) notifyOnRetryEx(attempt AttemptInfo) {
+	p.logRetry(attempt)
+	if p.onRetryEx != nil {
+		p.onRetryEx(attempt)
+	}
+}
+
+func (p *policy) notifyOnPanicEx(attempt AttemptInfo) {
+	p.logPanic(attempt)
+	if p.onPanicEx != nil {
+		p.onPanicEx(attempt)
+	}
+}
+
+func (p *policy) notifyOnTimeoutEx(attempt AttemptInfo) {
+	p.logTimeout(attempt)
+	if p.onTimeoutEx != nil {
+		p.onTimeoutEx(attempt)
+	}
+}
+
+func (p *policy) notifyOnAttemptTimeoutEx(attempt AttemptInfo) {
+	p.logAttemptTimeout(attempt)
+	if p.onAttemptTimeoutEx != nil {
+		p.onAttemptTimeoutEx(attempt)
+	}
+}
@@ -0,0 +1,171 @@
+package gotry
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOnRetryExReceivesFailedAttemptAndNextDelay(t *testing.T) {
+	var infos []AttemptInfo
+	policy := NewPolicy().WithRetryLimit(2).WithBackoff(NewConstantBackoff(time.Millisecond)).
+		WithName("payment").WithOperationKey("charge").
+		WithOnRetryEx(func(info AttemptInfo) {
+			infos = append(infos, info)
+		})
+	err := policy.TryMethod(errorMethod)
+	assert.Equal(t, ExpectedError, err)
+	assert.Equal(t, 2, len(infos))
+	for i, info := range infos {
+		assert.Equal(t, i+1, info.Attempt)
+		assert.Equal(t, ExpectedError, info.LastError)
+		assert.Equal(t, time.Millisecond, info.NextDelay)
+		assert.Equal(t, "payment", info.PolicyName)
+		assert.Equal(t, "charge", info.OperationKey)
+		assert.False(t, info.StartTime.IsZero())
+		assert.True(t, info.TotalElapsed >= info.Elapsed)
+	}
+	assert.True(t, infos[1].StartTime.After(infos[0].StartTime))
+	assert.True(t, infos[1].TotalElapsed >= time.Millisecond)
+}
+
+func TestOnRetryExNotFiredWithoutRetry(t *testing.T) {
+	fired := false
+	NewPolicy().WithRetryLimit(2).WithAbortOnErrors(ExpectedError).
+		WithOnRetryEx(func(AttemptInfo) {
+			fired = true
+		}).TryMethod(errorMethod)
+	assert.False(t, fired)
+}
+
+func TestOnPanicExReceivesAttemptAndStack(t *testing.T) {
+	var panicInfos []AttemptInfo
+	var retryInfos []AttemptInfo
+	policy := NewPolicy().WithRetryLimit(1).
+		WithOnPanicEx(func(info AttemptInfo) {
+			panicInfos = append(panicInfos, info)
+		}).
+		WithOnRetryEx(func(info AttemptInfo) {
+			retryInfos = append(retryInfos, info)
+		})
+	assert.PanicsWithValue(t, PanicContent, func() {
+		policy.TryMethod(panicMethod)
+	})
+	assert.Equal(t, 2, len(panicInfos))
+	for i, info := range panicInfos {
+		assert.Equal(t, i+1, info.Attempt)
+		assert.Equal(t, PanicContent, info.PanicValue)
+		assert.True(t, strings.Contains(string(info.Stack), "attemptinfo_test.go"))
+	}
+	assert.Equal(t, 1, len(retryInfos))
+	assert.Equal(t, PanicContent, retryInfos[0].PanicValue)
+}
+
+func TestOnAttemptTimeoutExReceivesAttempt(t *testing.T) {
+	var infos []AttemptInfo
+	var invoked int32
+	policy := NewPolicy().WithRetryLimit(1).WithAttemptTimeout(timeout).
+		WithOnAttemptTimeoutEx(func(info AttemptInfo) {
+			infos = append(infos, info)
+		})
+	funcReturn := policy.TryFunc(slowThenSuccessFunc(&invoked, 1))
+	assert.Nil(t, funcReturn.Err)
+	assert.Equal(t, 1, len(infos))
+	assert.Equal(t, 1, infos[0].Attempt)
+	assert.Equal(t, TimeoutError, infos[0].LastError)
+	assert.True(t, infos[0].Elapsed >= timeout)
+}
+
+func TestTimedOutAttemptCountedOnceInTotalElapsed(t *testing.T) {
+	var infos []AttemptInfo
+	var invoked int32
+	policy := NewPolicy().WithRetryLimit(1).WithAttemptTimeout(timeout).
+		WithOnAttemptDone(func(info AttemptInfo) {
+			infos = append(infos, info)
+		})
+	start := time.Now()
+	funcReturn := policy.TryFunc(slowThenSuccessFunc(&invoked, 2))
+	wallTime := time.Since(start)
+	assert.Equal(t, TimeoutError, funcReturn.Err)
+	assert.Equal(t, 2, len(infos))
+	for _, info := range infos {
+		assert.True(t, info.TotalElapsed <= wallTime, "attempt %d total elapsed %v exceeds wall time %v",
+			info.Attempt, info.TotalElapsed, wallTime)
+		assert.True(t, info.TotalElapsed >= info.Elapsed)
+	}
+}
+
+func TestOnTimeoutExReceivesRunningAttempt(t *testing.T) {
+	infoChan := make(chan AttemptInfo, 1)
+	var invoked int32
+	policy := NewPolicy().WithRetryLimit(2).WithOverallTimeout(timeout * 3 / 2).WithOperationKey("slow").
+		WithOnTimeoutEx(func(info AttemptInfo) {
+			infoChan <- info
+		})
+	funcReturn := policy.TryFunc(slowThenSuccessFunc(&invoked, 3))
+	assert.Equal(t, TimeoutError, funcReturn.Err)
+	info := <-infoChan
+	assert.Equal(t, 1, info.Attempt)
+	assert.Equal(t, TimeoutError, info.LastError)
+	assert.Equal(t, "slow", info.OperationKey)
+	assert.True(t, info.Elapsed >= timeout)
+	assert.True(t, info.TotalElapsed >= timeout)
+}
+
+func TestMultipleOnRetryExEvents(t *testing.T) {
+	fired := 0
+	onRetry := func(AttemptInfo) {
+		fired++
+	}
+	NewPolicy().WithRetryLimit(1).WithOnRetryEx(onRetry).WithOnRetryEx(onRetry).TryMethod(errorMethod)
+	assert.Equal(t, 2, fired)
+}
+
+func TestOnAttemptDoneFiresForEveryAttempt(t *testing.T) {
+	var infos []AttemptInfo
+	invoked := 0
+	err := NewPolicy().WithRetryLimit(2).
+		WithOnAttemptDone(func(info AttemptInfo) {
+			infos = append(infos, info)
+		}).
+		TryMethod(func() error {
+			invoked++
+			if invoked == 1 {
+				panic(PanicContent)
+			}
+			if invoked == 2 {
+				return ExpectedError
+			}
+			return nil
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(infos))
+	assert.Equal(t, PanicContent, infos[0].PanicValue)
+	assert.False(t, infos[0].Succeeded)
+	assert.Equal(t, ExpectedError, infos[1].LastError)
+	assert.False(t, infos[1].Succeeded)
+	assert.True(t, infos[2].Succeeded)
+}
+
+func TestOnExecutionDoneReportsOutcome(t *testing.T) {
+	var infos []AttemptInfo
+	policy := NewPolicy().WithRetryLimit(1).WithName("payment").
+		WithOnExecutionDone(func(info AttemptInfo) {
+			infos = append(infos, info)
+		})
+	assert.Equal(t, ExpectedError, policy.TryMethod(errorMethod))
+	assert.Nil(t, policy.TryMethod(successMethod))
+	assert.Panics(t, func() {
+		policy.WithLetItPanic().TryMethod(panicMethod)
+	})
+	assert.Equal(t, 3, len(infos))
+	assert.Equal(t, 2, infos[0].Attempt)
+	assert.Equal(t, ExpectedError, infos[0].LastError)
+	assert.False(t, infos[0].Succeeded)
+	assert.Equal(t, "payment", infos[0].PolicyName)
+	assert.True(t, infos[1].Succeeded)
+	assert.Equal(t, PanicContent, infos[2].PanicValue)
+	assert.False(t, infos[2].Succeeded)
+}
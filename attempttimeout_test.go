@@ -0,0 +1,77 @@
+package gotry
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func slowThenSuccessFunc(invoked *int32, slowAttempts int32) Func {
+	return func() FuncReturn {
+		if atomic.AddInt32(invoked, 1) <= slowAttempts {
+			time.Sleep(waitTime)
+		}
+		return successFunc()
+	}
+}
+
+func TestAttemptTimeoutIsRetried(t *testing.T) {
+	var invoked int32
+	var timedOut []int
+	var retriedErrors []error
+	policy := NewPolicy().WithRetryLimit(2).WithAttemptTimeout(timeout).
+		WithOnAttemptTimeout(func(retriedCount int, attemptTimeout time.Duration) {
+			assert.Equal(t, timeout, attemptTimeout)
+			timedOut = append(timedOut, retriedCount)
+		}).
+		WithOnFuncRetry(func(retriedCount int, returnValue interface{}, err error) {
+			retriedErrors = append(retriedErrors, err)
+		})
+	funcReturn := policy.TryFunc(slowThenSuccessFunc(&invoked, 1))
+	assert.Nil(t, funcReturn.Err)
+	assert.Equal(t, ExpectedReturnValue, funcReturn.ReturnValue)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&invoked))
+	assert.Equal(t, []int{0}, timedOut)
+	assert.Equal(t, []error{TimeoutError}, retriedErrors)
+}
+
+func TestAttemptTimeoutExhaustsRetries(t *testing.T) {
+	var invoked int32
+	err := NewPolicy().WithRetryLimit(1).WithAttemptTimeout(timeout).TryMethod(func() error {
+		atomic.AddInt32(&invoked, 1)
+		time.Sleep(waitTime)
+		return nil
+	})
+	assert.Equal(t, TimeoutError, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&invoked))
+}
+
+func TestAttemptTimeoutFiresOnTimeout(t *testing.T) {
+	var timeouts []time.Duration
+	var invoked int32
+	policy := NewPolicy().WithRetryLimit(1).WithAttemptTimeout(timeout).WithOverallTimeout(time.Second).
+		WithOnTimeout(func(expired time.Duration) {
+			timeouts = append(timeouts, expired)
+		})
+	funcReturn := policy.TryFunc(slowThenSuccessFunc(&invoked, 1))
+	assert.Nil(t, funcReturn.Err)
+	assert.Equal(t, []time.Duration{timeout}, timeouts, "only the attempt timeout should fire")
+}
+
+func TestOverallTimeoutStopsAttemptsWithinBudget(t *testing.T) {
+	var invoked int32
+	policy := NewPolicy().WithRetryForever().WithAttemptTimeout(time.Second).WithOverallTimeout(timeout)
+	funcReturn := policy.TryFunc(slowThenSuccessFunc(&invoked, 1))
+	assert.Equal(t, TimeoutError, funcReturn.Err)
+}
+
+func TestPanicInAttemptWithTimeout(t *testing.T) {
+	mockRetry, onPanic := prepareMockOnPanicFuncWithoutOnError()
+	policy := NewPolicy().WithRetryLimit(1).WithAttemptTimeout(time.Second).WithOnPanic(onPanic)
+	assert.PanicsWithValue(t, PanicContent, func() {
+		policy.TryFunc(panicFunc)
+	})
+	mockRetry.AssertNumberOfCalls(t, OnPanicMethodName, 2)
+}
@@ -0,0 +1,99 @@
+package gotry
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff decides how long a policy waits before the next retry. retriedCount is the count
+// passed to OnFuncError for the attempt that just failed, lastDelay is the previous wait
+// (zero before the first retry).
+type Backoff interface {
+	Delay(retriedCount int, lastDelay time.Duration) time.Duration
+}
+
+// BackoffFunc adapts an ordinary function to Backoff.
+type BackoffFunc func(retriedCount int, lastDelay time.Duration) time.Duration
+
+func (f BackoffFunc) Delay(retriedCount int, lastDelay time.Duration) time.Duration {
+	return f(retriedCount, lastDelay)
+}
+
+type constantBackoff struct {
+	delay time.Duration
+}
+
+// NewConstantBackoff waits the same delay before every retry.
+func NewConstantBackoff(delay time.Duration) Backoff {
+	return &constantBackoff{delay: delay}
+}
+
+func (b *constantBackoff) Delay(int, time.Duration) time.Duration {
+	return b.delay
+}
+
+type linearBackoff struct {
+	initial   time.Duration
+	increment time.Duration
+}
+
+// NewLinearBackoff waits initial before the first retry and adds increment for each retry after.
+func NewLinearBackoff(initial time.Duration, increment time.Duration) Backoff {
+	return &linearBackoff{initial: initial, increment: increment}
+}
+
+func (b *linearBackoff) Delay(retriedCount int, _ time.Duration) time.Duration {
+	return b.initial + b.increment*time.Duration(retriedCount)
+}
+
+type exponentialBackoff struct {
+	initial    time.Duration
+	multiplier float64
+	maxDelay   time.Duration
+}
+
+// NewExponentialBackoff waits initial before the first retry and multiplies the delay by multiplier
+// for each retry after, never waiting longer than maxDelay. A maxDelay of zero means no cap.
+func NewExponentialBackoff(initial time.Duration, multiplier float64, maxDelay time.Duration) Backoff {
+	return &exponentialBackoff{initial: initial, multiplier: multiplier, maxDelay: maxDelay}
+}
+
+func (b *exponentialBackoff) Delay(retriedCount int, _ time.Duration) time.Duration {
+	delay := float64(b.initial) * math.Pow(b.multiplier, float64(retriedCount))
+	return capDelay(delay, b.maxDelay)
+}
+
+type decorrelatedJitterBackoff struct {
+	base     time.Duration
+	maxDelay time.Duration
+}
+
+// NewDecorrelatedJitterBackoff picks a random delay between base and three times the last delay,
+// capped by maxDelay, so concurrent callers spread out instead of retrying in lockstep.
+// A maxDelay of zero means no cap.
+func NewDecorrelatedJitterBackoff(base time.Duration, maxDelay time.Duration) Backoff {
+	return &decorrelatedJitterBackoff{base: base, maxDelay: maxDelay}
+}
+
+func (b *decorrelatedJitterBackoff) Delay(_ int, lastDelay time.Duration) time.Duration {
+	if lastDelay < b.base {
+		lastDelay = b.base
+	}
+	upper := float64(lastDelay) * 3
+	delay := float64(b.base)
+	if spread := upper - delay; spread >= 1 {
+		delay += float64(rand.Int63n(int64(math.Min(spread, math.MaxInt64/2))))
+	}
+	return capDelay(delay, b.maxDelay)
+}
+
+func capDelay(delay float64, maxDelay time.Duration) time.Duration {
+	if maxDelay > 0 && delay > float64(maxDelay) {
+		return maxDelay
+	}
+	if delay >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(delay)
+}
@@ -0,0 +1,159 @@
+package gotry
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstantBackoff(t *testing.T) {
+	backoff := NewConstantBackoff(time.Second)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, time.Second, backoff.Delay(i, time.Second))
+	}
+}
+
+func TestLinearBackoff(t *testing.T) {
+	backoff := NewLinearBackoff(time.Second, time.Millisecond)
+	assert.Equal(t, time.Second, backoff.Delay(0, 0))
+	assert.Equal(t, time.Second+2*time.Millisecond, backoff.Delay(2, 0))
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := NewExponentialBackoff(time.Millisecond, 2, 5*time.Millisecond)
+	assert.Equal(t, time.Millisecond, backoff.Delay(0, 0))
+	assert.Equal(t, 4*time.Millisecond, backoff.Delay(2, 0))
+	assert.Equal(t, 5*time.Millisecond, backoff.Delay(3, 0), "delay should be capped by max delay")
+	assert.Equal(t, 5*time.Millisecond, backoff.Delay(1000, 0), "delay should be capped by max delay")
+}
+
+func TestDecorrelatedJitterBackoff(t *testing.T) {
+	const base = time.Millisecond
+	const maxDelay = 20 * time.Millisecond
+	backoff := NewDecorrelatedJitterBackoff(base, maxDelay)
+	var delay time.Duration
+	for i := 0; i < 100; i++ {
+		last := delay
+		if last < base {
+			last = base
+		}
+		delay = backoff.Delay(i, delay)
+		assert.True(t, delay >= base, "delay should not be shorter than base")
+		assert.True(t, delay <= maxDelay, "delay should be capped by max delay")
+		assert.True(t, delay <= 3*last, "delay should not exceed three times last delay")
+	}
+}
+
+func TestPolicyWaitsBackoffBetweenRetries(t *testing.T) {
+	const delay = 5 * time.Millisecond
+	var delays []time.Duration
+	backoff := BackoffFunc(func(retriedCount int, lastDelay time.Duration) time.Duration {
+		delays = append(delays, lastDelay)
+		return delay
+	})
+	start := time.Now()
+	err := NewPolicy().WithRetryLimit(2).WithBackoff(backoff).TryMethod(errorMethod)
+	assert.Equal(t, ExpectedError, err)
+	assert.True(t, time.Since(start) >= 2*delay)
+	assert.Equal(t, []time.Duration{0, delay}, delays)
+}
+
+func TestNoBackoffAfterLastAttempt(t *testing.T) {
+	invoked := 0
+	backoff := BackoffFunc(func(int, time.Duration) time.Duration {
+		invoked++
+		return 0
+	})
+	_ = NewPolicy().WithRetryLimit(1).WithBackoff(backoff).TryFunc(errorFunc)
+	assert.Equal(t, 1, invoked)
+}
+
+func TestCancellationInterruptsBackoff(t *testing.T) {
+	c := NewCancellation()
+	policy := NewPolicy().WithRetryForever().WithBackoff(NewConstantBackoff(time.Hour)).
+		WithOnMethodRetry(func(int, error) {
+			c.Cancel()
+		})
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- policy.TryMethodWithCancellation(errorMethod, c)
+	}()
+	select {
+	case err := <-errChan:
+		assert.Equal(t, ExpectedError, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "cancellation should interrupt backoff")
+	}
+}
+
+func TestTimeoutInterruptsBackoff(t *testing.T) {
+	policy := NewPolicy().WithRetryForever().WithBackoff(NewConstantBackoff(time.Hour)).WithTimeout(timeout)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- policy.TryMethod(errorMethod)
+	}()
+	select {
+	case err := <-errChan:
+		assert.Equal(t, TimeoutError, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "timeout should interrupt backoff")
+	}
+}
+
+type customCancellation struct {
+	requested int32
+}
+
+func (c *customCancellation) IsCancellationRequested() bool {
+	return atomic.LoadInt32(&c.requested) == 1
+}
+
+func (c *customCancellation) Cancel() bool {
+	return atomic.CompareAndSwapInt32(&c.requested, 0, 1)
+}
+
+func TestCustomCancellationInterruptsBackoff(t *testing.T) {
+	c := &customCancellation{}
+	policy := NewPolicy().WithRetryForever().WithBackoff(NewConstantBackoff(time.Hour))
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- policy.TryMethodWithCancellation(errorMethod, c)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	c.Cancel()
+	select {
+	case err := <-errChan:
+		assert.Equal(t, ExpectedError, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "custom cancellation should interrupt backoff")
+	}
+}
+
+func TestCustomCancellationInterruptsBackoffWithinTimeout(t *testing.T) {
+	for name, strategy := range map[string]TimeoutStrategy{
+		"optimistic":  TimeoutOptimistic,
+		"pessimistic": TimeoutPessimistic,
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := &customCancellation{}
+			policy := NewPolicy().
+				WithRetryForever().
+				WithBackoff(NewConstantBackoff(time.Hour)).
+				WithTimeout(time.Minute).
+				WithTimeoutStrategy(strategy)
+			errChan := make(chan error, 1)
+			go func() {
+				errChan <- policy.TryMethodWithCancellation(errorMethod, c)
+			}()
+			time.Sleep(20 * time.Millisecond)
+			c.Cancel()
+			select {
+			case err := <-errChan:
+				assert.Equal(t, ExpectedError, err)
+			case <-time.After(time.Second):
+				assert.Fail(t, "custom cancellation should interrupt backoff within a timeout")
+			}
+		})
+	}
+}
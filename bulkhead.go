@@ -0,0 +1,169 @@
+package gotry
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+type OnBulkheadRejected func(rejection *BulkheadRejectedError)
+
+// Bulkhead limits how many bodies run at the same time, extra calls wait in a bounded queue.
+// Bulkheads derived from one another by With... share the same slots and queue.
+type Bulkhead interface {
+	WithQueueTimeout(queueTimeout time.Duration) Bulkhead
+	WithOnBulkheadRejected(onRejected OnBulkheadRejected) Bulkhead
+	WithClock(clock Clock) Bulkhead
+	AvailableSlots() int
+	QueueLength() int
+	TryFunc(funcBody Func) FuncReturn
+	TryMethod(methodBody Method) error
+	TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn
+	TryMethodContext(ctx context.Context, methodBody MethodWithContext) error
+}
+
+// BulkheadRejectedError is returned instead of calling body when the queue is full, or when the call
+// was queued but no slot was freed within queue timeout.
+type BulkheadRejectedError struct {
+	MaxParallelism   int
+	MaxQueue         int
+	QueueWaitExpired bool
+}
+
+func (e *BulkheadRejectedError) Error() string {
+	if e.QueueWaitExpired {
+		return fmt.Sprintf("bulkhead rejected: no slot freed within queue timeout, max parallelism %d", e.MaxParallelism)
+	}
+	return fmt.Sprintf("bulkhead rejected: max parallelism %d and max queue %d reached", e.MaxParallelism, e.MaxQueue)
+}
+
+type bulkhead struct {
+	maxParallelism int
+	maxQueue       int
+	queueTimeout   time.Duration
+	onRejected     OnBulkheadRejected
+	clock          Clock
+	compartment    *compartment
+}
+
+type compartment struct {
+	// admissions bounds running and queued calls together, slots bounds running calls only
+	admissions chan struct{}
+	slots      chan struct{}
+	queued     int32
+}
+
+// NewBulkhead lets maxParallelism bodies run at the same time and up to maxQueue calls wait for a slot,
+// calls beyond that are rejected with BulkheadRejectedError.
+func NewBulkhead(maxParallelism int, maxQueue int) Bulkhead {
+	if maxParallelism <= 0 || maxQueue < 0 {
+		panic("gotry: bulkhead needs positive max parallelism and non-negative max queue")
+	}
+	return &bulkhead{
+		maxParallelism: maxParallelism,
+		maxQueue:       maxQueue,
+		clock:          SystemClock,
+		compartment: &compartment{
+			admissions: make(chan struct{}, maxParallelism+maxQueue),
+			slots:      make(chan struct{}, maxParallelism),
+		},
+	}
+}
+
+// WithQueueTimeout rejects queued calls that could not get a slot within queueTimeout, zero waits forever.
+func (b bulkhead) WithQueueTimeout(queueTimeout time.Duration) Bulkhead {
+	b.queueTimeout = queueTimeout
+	return &b
+}
+
+func (b bulkhead) WithOnBulkheadRejected(onRejected OnBulkheadRejected) Bulkhead {
+	originEvent := b.onRejected
+	if originEvent != nil {
+		b.onRejected = func(rejection *BulkheadRejectedError) {
+			originEvent(rejection)
+			onRejected(rejection)
+		}
+	} else {
+		b.onRejected = onRejected
+	}
+	return &b
+}
+
+func (b bulkhead) WithClock(clock Clock) Bulkhead {
+	b.clock = clockOrSystem(clock)
+	return &b
+}
+
+func (b *bulkhead) AvailableSlots() int {
+	return b.maxParallelism - len(b.compartment.slots)
+}
+
+func (b *bulkhead) QueueLength() int {
+	return int(atomic.LoadInt32(&b.compartment.queued))
+}
+
+func (b *bulkhead) TryFunc(funcBody Func) FuncReturn {
+	return b.TryFuncContext(context.Background(), funcBody.ignoreContext())
+}
+
+func (b *bulkhead) TryMethod(methodBody Method) error {
+	return b.TryFunc(methodBody.convertToFunc()).Err
+}
+
+// TryFuncContext stops waiting in the queue once ctx is done, and returns ctx.Err() without calling body.
+func (b *bulkhead) TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn {
+	if err := b.acquire(ctx); err != nil {
+		return FuncReturn{Valid: false, Err: err}
+	}
+	defer b.release()
+	return funcBody(ctx)
+}
+
+func (b *bulkhead) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	return b.TryFuncContext(ctx, methodBody.convertToFunc()).Err
+}
+
+func (b *bulkhead) acquire(ctx context.Context) error {
+	c := b.compartment
+	select {
+	case c.admissions <- struct{}{}:
+	default:
+		return b.reject(&BulkheadRejectedError{MaxParallelism: b.maxParallelism, MaxQueue: b.maxQueue})
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	default:
+	}
+	atomic.AddInt32(&c.queued, 1)
+	defer atomic.AddInt32(&c.queued, -1)
+	var expired <-chan time.Time
+	if b.queueTimeout > 0 {
+		timer := b.clock.NewTimer(b.queueTimeout)
+		defer timer.Stop()
+		expired = timer.C()
+	}
+	select {
+	case c.slots <- struct{}{}:
+		return nil
+	case <-expired:
+		<-c.admissions
+		return b.reject(&BulkheadRejectedError{MaxParallelism: b.maxParallelism, MaxQueue: b.maxQueue, QueueWaitExpired: true})
+	case <-ctx.Done():
+		<-c.admissions
+		return ctx.Err()
+	}
+}
+
+func (b *bulkhead) release() {
+	<-b.compartment.slots
+	<-b.compartment.admissions
+}
+
+func (b *bulkhead) reject(rejection *BulkheadRejectedError) error {
+	if b.onRejected != nil {
+		b.onRejected(rejection)
+	}
+	return rejection
+}
@@ -0,0 +1,144 @@
+package gotry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// occupy starts a call which holds a slot until release is closed, and waits until it runs.
+func occupy(bulkhead Bulkhead, release chan struct{}) <-chan error {
+	started := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- bulkhead.TryMethod(func() error {
+			close(started)
+			<-release
+			return nil
+		})
+	}()
+	<-started
+	return done
+}
+
+func waitQueueLength(bulkhead Bulkhead, length int) {
+	deadline := time.Now().Add(time.Second)
+	for bulkhead.QueueLength() != length && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestBulkheadRunsBody(t *testing.T) {
+	bulkhead := NewBulkhead(1, 0)
+	funcReturn := bulkhead.TryFunc(successFunc)
+	assert.Equal(t, ExpectedReturnValue, funcReturn.ReturnValue)
+	assert.Equal(t, 1, bulkhead.AvailableSlots())
+}
+
+func TestBulkheadRejectsWhenQueueIsFull(t *testing.T) {
+	var rejections []*BulkheadRejectedError
+	bulkhead := NewBulkhead(1, 0).WithOnBulkheadRejected(func(rejection *BulkheadRejectedError) {
+		rejections = append(rejections, rejection)
+	})
+	release := make(chan struct{})
+	done := occupy(bulkhead, release)
+	assert.Equal(t, 0, bulkhead.AvailableSlots())
+	err := bulkhead.TryMethod(successMethod)
+	var rejectedError *BulkheadRejectedError
+	assert.True(t, errors.As(err, &rejectedError))
+	assert.False(t, rejectedError.QueueWaitExpired)
+	assert.Equal(t, []*BulkheadRejectedError{rejectedError}, rejections)
+	close(release)
+	assert.Nil(t, <-done)
+	assert.Equal(t, 1, bulkhead.AvailableSlots())
+}
+
+func TestBulkheadQueuesCalls(t *testing.T) {
+	bulkhead := NewBulkhead(1, 1)
+	release := make(chan struct{})
+	done := occupy(bulkhead, release)
+	queued := make(chan error, 1)
+	go func() {
+		queued <- bulkhead.TryMethod(successMethod)
+	}()
+	waitQueueLength(bulkhead, 1)
+	assert.Equal(t, 1, bulkhead.QueueLength())
+	close(release)
+	assert.Nil(t, <-done)
+	assert.Nil(t, <-queued)
+	assert.Equal(t, 0, bulkhead.QueueLength())
+}
+
+func TestBulkheadQueueTimeout(t *testing.T) {
+	bulkhead := NewBulkhead(1, 1).WithQueueTimeout(timeout)
+	release := make(chan struct{})
+	done := occupy(bulkhead, release)
+	err := bulkhead.TryMethod(successMethod)
+	var rejectedError *BulkheadRejectedError
+	assert.True(t, errors.As(err, &rejectedError))
+	assert.True(t, rejectedError.QueueWaitExpired)
+	assert.Equal(t, 0, bulkhead.QueueLength())
+	close(release)
+	assert.Nil(t, <-done)
+	assert.Nil(t, bulkhead.TryMethod(successMethod), "expired queue wait should give back its admission")
+}
+
+func TestBulkheadQueueWaitEndsWithContext(t *testing.T) {
+	bulkhead := NewBulkhead(1, 1)
+	release := make(chan struct{})
+	done := occupy(bulkhead, release)
+	called := false
+	err := NewPolicyWrap(NewPolicy().WithTimeout(timeout), bulkhead).TryMethodContext(context.Background(),
+		func(context.Context) error {
+			called = true
+			return nil
+		})
+	assert.Equal(t, TimeoutError, err)
+	waitQueueLength(bulkhead, 0)
+	assert.Equal(t, 0, bulkhead.QueueLength())
+
+	queued := make(chan error, 1)
+	go func() {
+		queued <- bulkhead.TryMethod(successMethod)
+	}()
+	waitQueueLength(bulkhead, 1)
+	assert.Equal(t, 1, bulkhead.QueueLength(), "cancelled queue wait should give back its admission")
+	close(release)
+	assert.Nil(t, <-done)
+	assert.Nil(t, <-queued)
+	assert.False(t, called)
+}
+
+func TestBulkheadReleasesSlotOnPanic(t *testing.T) {
+	bulkhead := NewBulkhead(1, 0)
+	assert.Panics(t, func() {
+		_ = bulkhead.TryMethod(panicMethod)
+	})
+	assert.Equal(t, 1, bulkhead.AvailableSlots())
+}
+
+func TestRetryWrapsBulkhead(t *testing.T) {
+	bulkhead := NewBulkhead(1, 0)
+	release := make(chan struct{})
+	done := occupy(bulkhead, release)
+	retried := 0
+	policy := NewPolicy().WithRetryForever().WithBackoff(NewConstantBackoff(time.Millisecond)).
+		WithOnMethodRetry(func(int, error) {
+			retried++
+			if retried == 2 {
+				close(release)
+				<-done
+			}
+		})
+	assert.Nil(t, NewPolicyWrap(policy, bulkhead).TryMethod(successMethod))
+	assert.Equal(t, 2, retried)
+}
+
+func TestInvalidBulkhead(t *testing.T) {
+	assert.Panics(t, func() {
+		NewBulkhead(0, 1)
+	})
+}
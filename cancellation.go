@@ -1,6 +1,11 @@
 package gotry
 
-import "sync/atomic"
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
 
 const cancellationNotRequestedFlag = 0
 const cancellationRequestedFlag = 1
@@ -11,17 +16,121 @@ type Cancellation interface {
 
 type cancellation struct {
 	isCancellationRequestedFlag int32
+	parent                      context.Context
+	// foreignParent is the parent cancellation when it is implemented outside this package, it
+	// cannot signal parent so it is checked by IsCancellationRequested and polled by waits.
+	foreignParent               Cancellation
+	initOnce                    sync.Once
+	ctx                         context.Context
+	cancel                      context.CancelFunc
 }
 
 func NewCancellation() Cancellation{
 	return &cancellation{}
 }
 
+// newLinkedCancellation returns a cancellation that is also signalled when parent is cancelled,
+// so waits guarded by the child can be interrupted by either of them.
+func newLinkedCancellation(parent Cancellation) *cancellation {
+	linked := newContextCancellation(contextOf(parent))
+	if hasForeignAncestor(parent) {
+		linked.foreignParent = parent
+	}
+	return linked
+}
+
+// newContextCancellation returns a cancellation that is also signalled when ctx is done.
+func newContextCancellation(ctx context.Context) *cancellation {
+	return &cancellation{parent: ctx}
+}
+
 func (cancellation *cancellation) IsCancellationRequested() bool {
-	return atomic.LoadInt32(&cancellation.isCancellationRequestedFlag) == cancellationRequestedFlag
+	return atomic.LoadInt32(&cancellation.isCancellationRequestedFlag) == cancellationRequestedFlag ||
+		(cancellation.parent != nil && cancellation.parent.Err() != nil) ||
+		(cancellation.foreignParent != nil && cancellation.foreignParent.IsCancellationRequested())
 }
 func (cancellation *cancellation) Cancel() bool {
-	return atomic.CompareAndSwapInt32(&cancellation.isCancellationRequestedFlag,
+	cancellation.init()
+	cancelled := atomic.CompareAndSwapInt32(&cancellation.isCancellationRequestedFlag,
 													cancellationNotRequestedFlag,
 													cancellationRequestedFlag)
+	cancellation.cancel()
+	return cancelled
+}
+
+func (cancellation *cancellation) context() context.Context {
+	cancellation.init()
+	return cancellation.ctx
+}
+
+func (cancellation *cancellation) init() {
+	cancellation.initOnce.Do(func() {
+		parent := cancellation.parent
+		if parent == nil {
+			parent = context.Background()
+		}
+		cancellation.ctx, cancellation.cancel = context.WithCancel(parent)
+	})
+}
+
+// cancellationPollInterval is how often waits check a Cancellation implemented outside this package.
+const cancellationPollInterval = 10 * time.Millisecond
+
+type contextCarrier interface {
+	context() context.Context
+}
+
+// contextOf returns a context that is done once c is cancelled. Cancellations implemented outside
+// this package cannot signal us, so they get a context that is never done, waitOrCancel polls them.
+// The same goes for a cancellation linked to one of them, see hasForeignAncestor.
+func contextOf(c Cancellation) context.Context {
+	if carrier, ok := c.(contextCarrier); ok {
+		return carrier.context()
+	}
+	return context.Background()
+}
+
+// waitOrCancel sleeps delay and reports false if cancellation was requested meanwhile.
+func waitOrCancel(clock Clock, delay time.Duration, cancellation Cancellation) bool {
+	if delay > 0 {
+		timer := clock.NewTimer(delay)
+		defer timer.Stop()
+		if hasForeignAncestor(cancellation) {
+			pollCancellation(clock, timer, cancellation)
+		} else {
+			select {
+			case <-timer.C():
+			case <-contextOf(cancellation).Done():
+			}
+		}
+	}
+	return cancellation == nil || !cancellation.IsCancellationRequested()
+}
+
+// hasForeignAncestor tells whether c, or a cancellation c is linked to, is implemented outside this
+// package, so waiting on its context alone would miss the cancellation.
+func hasForeignAncestor(c Cancellation) bool {
+	switch c := c.(type) {
+	case nil:
+		return false
+	case *cancellation:
+		return c.foreignParent != nil
+	default:
+		_, ok := c.(contextCarrier)
+		return !ok
+	}
+}
+
+// pollCancellation waits for timer unless cancellation is requested first.
+func pollCancellation(clock Clock, timer Timer, cancellation Cancellation) {
+	poll := clock.NewTimer(cancellationPollInterval)
+	defer poll.Stop()
+	for !cancellation.IsCancellationRequested() {
+		select {
+		case <-timer.C():
+			return
+		case <-poll.C():
+			poll.Reset(cancellationPollInterval)
+		}
+	}
 }
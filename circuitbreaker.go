@@ -0,0 +1,358 @@
+package gotry
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+type CircuitState int
+
+const (
+	CircuitClosed CircuitState = iota
+	CircuitOpen
+	CircuitHalfOpen
+	CircuitIsolated
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	case CircuitIsolated:
+		return "isolated"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// OnBreak fires once the circuit opens for breakDuration, or is isolated by Isolate with lastError nil
+// and breakDuration math.MaxInt64.
+type OnBreak func(lastError error, breakDuration time.Duration)
+type OnReset func()
+type OnHalfOpen func()
+
+// CircuitBreaker stops calling a failing dependency for a while once failures reach a threshold.
+// Breakers derived from one another by With... share the same circuit, so configure it once and
+// share the result across goroutines.
+type CircuitBreaker interface {
+	WithConsecutiveFailureThreshold(threshold int) CircuitBreaker
+	WithFailureRatioThreshold(ratio float64, samplingWindow time.Duration, minimumThroughput int) CircuitBreaker
+	WithBreakDuration(breakDuration time.Duration) CircuitBreaker
+	WithOnBreak(onBreak OnBreak) CircuitBreaker
+	WithOnReset(onReset OnReset) CircuitBreaker
+	WithOnHalfOpen(onHalfOpen OnHalfOpen) CircuitBreaker
+	WithClock(clock Clock) CircuitBreaker
+	State() CircuitState
+	Isolate()
+	Reset()
+	TryFunc(funcBody Func) FuncReturn
+	TryMethod(methodBody Method) error
+	TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn
+	TryMethodContext(ctx context.Context, methodBody MethodWithContext) error
+}
+
+// BrokenCircuitError is returned instead of calling the body while the circuit is open or isolated.
+type BrokenCircuitError struct {
+	State     CircuitState
+	LastError error
+}
+
+func (e *BrokenCircuitError) Error() string {
+	if e.LastError == nil {
+		return fmt.Sprintf("circuit is %s", e.State)
+	}
+	return fmt.Sprintf("circuit is %s, last error: %v", e.State, e.LastError)
+}
+
+const defaultConsecutiveFailureThreshold = 5
+const defaultBreakDuration = 5 * time.Second
+
+type circuitBreaker struct {
+	consecutiveFailureThreshold int
+	failureRatio                float64
+	samplingWindow              time.Duration
+	minimumThroughput           int
+	breakDuration               time.Duration
+	onBreak                     OnBreak
+	onReset                     OnReset
+	onHalfOpen                  OnHalfOpen
+	clock                       Clock
+	circuit                     *circuit
+}
+
+type circuit struct {
+	mutex               sync.Mutex
+	state               CircuitState
+	consecutiveFailures int
+	health              rollingCounts
+	openedUntil         time.Time
+	lastError           error
+	probing             bool
+}
+
+// NewCircuitBreaker returns a breaker that opens for 5 seconds after 5 consecutive failures.
+// An error, an invalid return value or a panic is counted as a failure.
+func NewCircuitBreaker() CircuitBreaker {
+	return &circuitBreaker{
+		consecutiveFailureThreshold: defaultConsecutiveFailureThreshold,
+		breakDuration:               defaultBreakDuration,
+		clock:                       SystemClock,
+		circuit:                     &circuit{},
+	}
+}
+
+// WithConsecutiveFailureThreshold opens the circuit after threshold failures in a row, zero disables it.
+func (b circuitBreaker) WithConsecutiveFailureThreshold(threshold int) CircuitBreaker {
+	b.consecutiveFailureThreshold = threshold
+	return &b
+}
+
+// WithFailureRatioThreshold opens the circuit once failures make up at least ratio of the calls
+// within samplingWindow, provided there were at least minimumThroughput calls. It works together
+// with the consecutive failure threshold, whichever is reached first opens the circuit.
+func (b circuitBreaker) WithFailureRatioThreshold(ratio float64, samplingWindow time.Duration, minimumThroughput int) CircuitBreaker {
+	b.failureRatio = ratio
+	b.samplingWindow = samplingWindow
+	b.minimumThroughput = minimumThroughput
+	return &b
+}
+
+func (b circuitBreaker) WithBreakDuration(breakDuration time.Duration) CircuitBreaker {
+	b.breakDuration = breakDuration
+	return &b
+}
+
+func (b circuitBreaker) WithOnBreak(onBreak OnBreak) CircuitBreaker {
+	originEvent := b.onBreak
+	if originEvent != nil {
+		b.onBreak = func(lastError error, breakDuration time.Duration) {
+			originEvent(lastError, breakDuration)
+			onBreak(lastError, breakDuration)
+		}
+	} else {
+		b.onBreak = onBreak
+	}
+	return &b
+}
+
+func (b circuitBreaker) WithOnReset(onReset OnReset) CircuitBreaker {
+	originEvent := b.onReset
+	if originEvent != nil {
+		b.onReset = func() {
+			originEvent()
+			onReset()
+		}
+	} else {
+		b.onReset = onReset
+	}
+	return &b
+}
+
+func (b circuitBreaker) WithOnHalfOpen(onHalfOpen OnHalfOpen) CircuitBreaker {
+	originEvent := b.onHalfOpen
+	if originEvent != nil {
+		b.onHalfOpen = func() {
+			originEvent()
+			onHalfOpen()
+		}
+	} else {
+		b.onHalfOpen = onHalfOpen
+	}
+	return &b
+}
+
+func (b circuitBreaker) WithClock(clock Clock) CircuitBreaker {
+	b.clock = clockOrSystem(clock)
+	return &b
+}
+
+func (b *circuitBreaker) State() CircuitState {
+	c := b.circuit
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.state == CircuitOpen && !b.clock.Now().Before(c.openedUntil) {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// Isolate holds the circuit open until Reset is called.
+func (b *circuitBreaker) Isolate() {
+	c := b.circuit
+	c.mutex.Lock()
+	c.state = CircuitIsolated
+	c.probing = false
+	c.mutex.Unlock()
+	b.notifyOnBreak(nil, time.Duration(math.MaxInt64))
+}
+
+// Reset closes the circuit and forgets all failures recorded so far.
+func (b *circuitBreaker) Reset() {
+	b.circuit.mutex.Lock()
+	b.circuit.close()
+	b.circuit.mutex.Unlock()
+	b.notifyOnReset()
+}
+
+func (b *circuitBreaker) TryFunc(funcBody Func) FuncReturn {
+	return b.TryFuncContext(context.Background(), funcBody.ignoreContext())
+}
+
+func (b *circuitBreaker) TryFuncContext(ctx context.Context, funcBody FuncWithContext) (funcReturn FuncReturn) {
+	if err := b.acquire(); err != nil {
+		return FuncReturn{Valid: false, Err: err}
+	}
+	panicked := true
+	defer func() {
+		if panicked {
+			panicErr := recover()
+			b.onFailure(newPanicError(panicErr))
+			panic(panicErr)
+		}
+	}()
+	funcReturn = funcBody(ctx)
+	panicked = false
+	if success(funcReturn) {
+		b.onSuccess()
+	} else {
+		b.onFailure(funcReturn.Err)
+	}
+	return
+}
+
+func (b *circuitBreaker) TryMethod(methodBody Method) error {
+	return b.TryFunc(methodBody.convertToFunc()).Err
+}
+
+func (b *circuitBreaker) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	return b.TryFuncContext(ctx, methodBody.convertToFunc()).Err
+}
+
+// acquire returns BrokenCircuitError if the call is not allowed to go through.
+func (b *circuitBreaker) acquire() error {
+	c := b.circuit
+	c.mutex.Lock()
+	halfOpened := false
+	if c.state == CircuitOpen && !b.clock.Now().Before(c.openedUntil) {
+		c.state = CircuitHalfOpen
+		halfOpened = true
+	}
+	var err error
+	switch {
+	case c.state == CircuitOpen || c.state == CircuitIsolated:
+		err = &BrokenCircuitError{State: c.state, LastError: c.lastError}
+	case c.state == CircuitHalfOpen && c.probing:
+		// only one trial call is let through while half-open
+		err = &BrokenCircuitError{State: c.state, LastError: c.lastError}
+	case c.state == CircuitHalfOpen:
+		c.probing = true
+	}
+	c.mutex.Unlock()
+	if halfOpened {
+		b.notifyOnHalfOpen()
+	}
+	return err
+}
+
+func (b *circuitBreaker) onSuccess() {
+	c := b.circuit
+	c.mutex.Lock()
+	reset := false
+	switch c.state {
+	case CircuitHalfOpen:
+		c.close()
+		reset = true
+	case CircuitClosed:
+		c.consecutiveFailures = 0
+		b.record(true)
+	}
+	c.mutex.Unlock()
+	if reset {
+		b.notifyOnReset()
+	}
+}
+
+func (b *circuitBreaker) onFailure(err error) {
+	c := b.circuit
+	c.mutex.Lock()
+	broken := false
+	switch c.state {
+	case CircuitHalfOpen:
+		broken = true
+	case CircuitClosed:
+		c.consecutiveFailures++
+		b.record(false)
+		broken = b.exceedThreshold()
+	}
+	if broken {
+		c.state = CircuitOpen
+		c.openedUntil = b.clock.Now().Add(b.breakDuration)
+		c.lastError = err
+		c.probing = false
+	}
+	c.mutex.Unlock()
+	if broken {
+		b.notifyOnBreak(err, b.breakDuration)
+	}
+}
+
+func (b *circuitBreaker) exceedThreshold() bool {
+	c := b.circuit
+	if b.consecutiveFailureThreshold > 0 && c.consecutiveFailures >= b.consecutiveFailureThreshold {
+		return true
+	}
+	if b.failureRatio <= 0 {
+		return false
+	}
+	successes, failures := c.health.sum(b.clock.Now(), b.samplingWindow)
+	total := successes + failures
+	return total >= b.minimumThroughput && float64(failures)/float64(total) >= b.failureRatio
+}
+
+const (
+	successCounter = iota
+	failureCounter
+)
+
+func (b *circuitBreaker) record(succeeded bool) {
+	if b.failureRatio <= 0 {
+		return
+	}
+	counter := failureCounter
+	if succeeded {
+		counter = successCounter
+	}
+	b.circuit.health.add(b.clock.Now(), b.samplingWindow, counter)
+}
+
+func (c *circuit) close() {
+	c.state = CircuitClosed
+	c.consecutiveFailures = 0
+	c.health.reset()
+	c.lastError = nil
+	c.probing = false
+}
+
+func (b *circuitBreaker) notifyOnBreak(lastError error, breakDuration time.Duration) {
+	if b.onBreak != nil {
+		b.onBreak(lastError, breakDuration)
+	}
+}
+
+func (b *circuitBreaker) notifyOnReset() {
+	if b.onReset != nil {
+		b.onReset()
+	}
+}
+
+func (b *circuitBreaker) notifyOnHalfOpen() {
+	if b.onHalfOpen != nil {
+		b.onHalfOpen()
+	}
+}
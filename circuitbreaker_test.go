@@ -0,0 +1,164 @@
+package gotry
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"testing"
+	"time"
+)
+
+const breakDuration = time.Millisecond * 20
+
+type CircuitBreakerTestSuite struct {
+	suite.Suite
+	breaker       CircuitBreaker
+	breakCount    int
+	resetCount    int
+	halfOpenCount int
+}
+
+func TestCircuitBreakerSuite(t *testing.T) {
+	suite.Run(t, &CircuitBreakerTestSuite{})
+}
+
+func (suite *CircuitBreakerTestSuite) SetupTest() {
+	suite.breakCount, suite.resetCount, suite.halfOpenCount = 0, 0, 0
+	suite.breaker = NewCircuitBreaker().
+		WithConsecutiveFailureThreshold(2).
+		WithBreakDuration(breakDuration).
+		WithOnBreak(func(lastError error, duration time.Duration) {
+			suite.breakCount++
+		}).
+		WithOnReset(func() {
+			suite.resetCount++
+		}).
+		WithOnHalfOpen(func() {
+			suite.halfOpenCount++
+		})
+}
+
+func (suite *CircuitBreakerTestSuite) breakCircuit() {
+	for i := 0; i < 2; i++ {
+		assert.Equal(suite.T(), ExpectedError, suite.breaker.TryMethod(errorMethod))
+	}
+}
+
+func (suite *CircuitBreakerTestSuite) TestSuccessKeepsCircuitClosed() {
+	funcReturn := suite.breaker.TryFunc(successFunc)
+	assert.Nil(suite.T(), funcReturn.Err)
+	assert.Equal(suite.T(), ExpectedReturnValue, funcReturn.ReturnValue)
+	assert.Equal(suite.T(), CircuitClosed, suite.breaker.State())
+}
+
+func (suite *CircuitBreakerTestSuite) TestSuccessResetsConsecutiveFailures() {
+	_ = suite.breaker.TryMethod(errorMethod)
+	_ = suite.breaker.TryMethod(successMethod)
+	_ = suite.breaker.TryMethod(errorMethod)
+	assert.Equal(suite.T(), CircuitClosed, suite.breaker.State())
+}
+
+func (suite *CircuitBreakerTestSuite) TestConsecutiveFailuresBreakCircuit() {
+	suite.breakCircuit()
+	assert.Equal(suite.T(), CircuitOpen, suite.breaker.State())
+	assert.Equal(suite.T(), 1, suite.breakCount)
+	invoked := false
+	err := suite.breaker.TryMethod(func() error {
+		invoked = true
+		return nil
+	})
+	var brokenCircuitError *BrokenCircuitError
+	assert.True(suite.T(), errors.As(err, &brokenCircuitError))
+	assert.Equal(suite.T(), ExpectedError, brokenCircuitError.LastError)
+	assert.False(suite.T(), invoked)
+}
+
+func (suite *CircuitBreakerTestSuite) TestHalfOpenSuccessResetsCircuit() {
+	suite.breakCircuit()
+	time.Sleep(breakDuration)
+	assert.Equal(suite.T(), CircuitHalfOpen, suite.breaker.State())
+	assert.Nil(suite.T(), suite.breaker.TryMethod(successMethod))
+	assert.Equal(suite.T(), CircuitClosed, suite.breaker.State())
+	assert.Equal(suite.T(), 1, suite.halfOpenCount)
+	assert.Equal(suite.T(), 1, suite.resetCount)
+}
+
+func (suite *CircuitBreakerTestSuite) TestHalfOpenFailureBreaksAgain() {
+	suite.breakCircuit()
+	time.Sleep(breakDuration)
+	assert.Equal(suite.T(), ExpectedError, suite.breaker.TryMethod(errorMethod))
+	assert.Equal(suite.T(), CircuitOpen, suite.breaker.State())
+	assert.Equal(suite.T(), 2, suite.breakCount)
+}
+
+func (suite *CircuitBreakerTestSuite) TestHalfOpenLetsOnlyOneTrialCall() {
+	suite.breakCircuit()
+	time.Sleep(breakDuration)
+	var secondErr error
+	_ = suite.breaker.TryMethod(func() error {
+		secondErr = suite.breaker.TryMethod(successMethod)
+		return nil
+	})
+	var brokenCircuitError *BrokenCircuitError
+	assert.True(suite.T(), errors.As(secondErr, &brokenCircuitError))
+	assert.Equal(suite.T(), CircuitHalfOpen, brokenCircuitError.State)
+}
+
+func (suite *CircuitBreakerTestSuite) TestPanicCountsAsFailure() {
+	for i := 0; i < 2; i++ {
+		assert.Panics(suite.T(), func() {
+			_ = suite.breaker.TryMethod(panicMethod)
+		})
+	}
+	assert.Equal(suite.T(), CircuitOpen, suite.breaker.State())
+}
+
+func (suite *CircuitBreakerTestSuite) TestIsolateAndReset() {
+	suite.breaker.Isolate()
+	assert.Equal(suite.T(), CircuitIsolated, suite.breaker.State())
+	time.Sleep(breakDuration)
+	var brokenCircuitError *BrokenCircuitError
+	assert.True(suite.T(), errors.As(suite.breaker.TryMethod(successMethod), &brokenCircuitError))
+	suite.breaker.Reset()
+	assert.Equal(suite.T(), CircuitClosed, suite.breaker.State())
+	assert.Nil(suite.T(), suite.breaker.TryMethod(successMethod))
+	assert.Equal(suite.T(), 1, suite.breakCount)
+	assert.Equal(suite.T(), 1, suite.resetCount)
+}
+
+func (suite *CircuitBreakerTestSuite) TestFailureRatioBreaksCircuit() {
+	breaker := NewCircuitBreaker().WithConsecutiveFailureThreshold(0).
+		WithFailureRatioThreshold(0.5, time.Minute, 4)
+	_ = breaker.TryMethod(successMethod)
+	_ = breaker.TryMethod(errorMethod)
+	_ = breaker.TryMethod(successMethod)
+	assert.Equal(suite.T(), CircuitClosed, breaker.State(), "should not break below minimum throughput")
+	_ = breaker.TryMethod(errorMethod)
+	assert.Equal(suite.T(), CircuitOpen, breaker.State())
+}
+
+func (suite *CircuitBreakerTestSuite) TestFailuresOutsideSamplingWindowExpire() {
+	const samplingWindow = time.Millisecond * 10
+	breaker := NewCircuitBreaker().WithConsecutiveFailureThreshold(0).
+		WithFailureRatioThreshold(0.5, samplingWindow, 2)
+	_ = breaker.TryMethod(errorMethod)
+	time.Sleep(samplingWindow)
+	_ = breaker.TryMethod(successMethod)
+	_ = breaker.TryMethod(successMethod)
+	assert.Equal(suite.T(), CircuitClosed, breaker.State())
+}
+
+func (suite *CircuitBreakerTestSuite) TestBreakerInFrontOfRetryPolicy() {
+	policy := NewPolicy().WithRetryLimit(1)
+	invoked := 0
+	for i := 0; i < 3; i++ {
+		_ = suite.breaker.TryMethod(func() error {
+			return policy.TryMethod(func() error {
+				invoked++
+				return ExpectedError
+			})
+		})
+	}
+	assert.Equal(suite.T(), 4, invoked)
+	assert.Equal(suite.T(), CircuitOpen, suite.breaker.State())
+}
@@ -0,0 +1,99 @@
+package gotry
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells time to policies, timeouts, backoff waits, circuit breakers, bulkheads, hedging, rate
+// limiters and retry budgets. Replace SystemClock by WithClock to control time in tests, see
+// gotrytest.FakeClock.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Sleep(d time.Duration)
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer is time.Timer of a Clock.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+	Reset(d time.Duration) bool
+}
+
+// SystemClock is the real time, used unless WithClock says otherwise.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{timer: time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
+
+func (t systemTimer) Reset(d time.Duration) bool {
+	return t.timer.Reset(d)
+}
+
+// WithClock makes the policy read time from clock.
+func (p policy) WithClock(clock Clock) Policy {
+	p.clock = clockOrSystem(clock)
+	return &p
+}
+
+// clockOrSystem treats nil as SystemClock.
+func clockOrSystem(clock Clock) Clock {
+	if clock == nil {
+		return SystemClock
+	}
+	return clock
+}
+
+// afterFunc is time.AfterFunc of clock, stop reports false once the timer has expired. Timer of a
+// Clock other than SystemClock has no callback, so a goroutine waits for it.
+func afterFunc(clock Clock, d time.Duration, f func()) (stop func() bool) {
+	if _, ok := clock.(systemClock); ok {
+		return time.AfterFunc(d, f).Stop
+	}
+	timer := clock.NewTimer(d)
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-timer.C():
+			f()
+		case <-stopped:
+		}
+	}()
+	var once sync.Once
+	return func() bool {
+		active := timer.Stop()
+		once.Do(func() {
+			close(stopped)
+		})
+		return active
+	}
+}
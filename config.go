@@ -0,0 +1,217 @@
+package gotry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// PolicyConfig describes a policy in JSON or YAML, so it can be tuned without recompiling.
+// Durations are written like "1.5s" or "300ms".
+type PolicyConfig struct {
+	Name         string `json:"name,omitempty" yaml:"name,omitempty"`
+	OperationKey string `json:"operationKey,omitempty" yaml:"operationKey,omitempty"`
+	// RetryLimit is how many retries follow the first attempt, no retry if neither it nor RetryForever is set.
+	RetryLimit   *int `json:"retryLimit,omitempty" yaml:"retryLimit,omitempty"`
+	RetryForever bool `json:"retryForever,omitempty" yaml:"retryForever,omitempty"`
+	LetItPanic   bool `json:"letItPanic,omitempty" yaml:"letItPanic,omitempty"`
+	// Timeout is the overall timeout.
+	Timeout        Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	AttemptTimeout Duration `json:"attemptTimeout,omitempty" yaml:"attemptTimeout,omitempty"`
+	// TimeoutStrategy is "pessimistic" (default) or "optimistic".
+	TimeoutStrategy  string         `json:"timeoutStrategy,omitempty" yaml:"timeoutStrategy,omitempty"`
+	Backoff          *BackoffConfig `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+	AggregatedErrors bool           `json:"aggregatedErrors,omitempty" yaml:"aggregatedErrors,omitempty"`
+}
+
+// BackoffConfig picks one of the backoffs of this package by Type, the other fields are its arguments.
+type BackoffConfig struct {
+	// Type is "constant", "linear", "exponential" or "decorrelatedJitter".
+	Type string `json:"type" yaml:"type"`
+	// Delay is the delay of constant backoff, and the first delay of the others.
+	Delay      Duration `json:"delay" yaml:"delay"`
+	Increment  Duration `json:"increment,omitempty" yaml:"increment,omitempty"`
+	Multiplier float64  `json:"multiplier,omitempty" yaml:"multiplier,omitempty"`
+	MaxDelay   Duration `json:"maxDelay,omitempty" yaml:"maxDelay,omitempty"`
+}
+
+// CircuitBreakerConfig describes a circuit breaker, unset fields keep the defaults of NewCircuitBreaker.
+type CircuitBreakerConfig struct {
+	ConsecutiveFailureThreshold *int     `json:"consecutiveFailureThreshold,omitempty" yaml:"consecutiveFailureThreshold,omitempty"`
+	FailureRatio                float64  `json:"failureRatio,omitempty" yaml:"failureRatio,omitempty"`
+	SamplingWindow              Duration `json:"samplingWindow,omitempty" yaml:"samplingWindow,omitempty"`
+	MinimumThroughput           int      `json:"minimumThroughput,omitempty" yaml:"minimumThroughput,omitempty"`
+	BreakDuration               Duration `json:"breakDuration,omitempty" yaml:"breakDuration,omitempty"`
+}
+
+// Duration is time.Duration written as text, e.g. "1m30s", in JSON and YAML.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	duration, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// ConfigError points at the field of a configuration holding an invalid value, by its JSON name.
+type ConfigError struct {
+	Field  string
+	Reason string
+}
+
+func (e *ConfigError) Error() string {
+	return fmt.Sprintf("invalid config at %s: %s", e.Field, e.Reason)
+}
+
+// NewPolicyFromConfig builds the policy described by config. It returns every invalid field as
+// ConfigError, joined by errors.Join.
+func NewPolicyFromConfig(config PolicyConfig) (Policy, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	policy := NewPolicy()
+	if config.Name != "" {
+		policy = policy.WithName(config.Name)
+	}
+	if config.OperationKey != "" {
+		policy = policy.WithOperationKey(config.OperationKey)
+	}
+	if config.RetryForever {
+		policy = policy.WithRetryForever()
+	} else if config.RetryLimit != nil {
+		policy = policy.WithRetryLimit(*config.RetryLimit)
+	}
+	if config.LetItPanic {
+		policy = policy.WithLetItPanic()
+	}
+	if config.Timeout > 0 {
+		policy = policy.WithOverallTimeout(time.Duration(config.Timeout))
+	}
+	if config.AttemptTimeout > 0 {
+		policy = policy.WithAttemptTimeout(time.Duration(config.AttemptTimeout))
+	}
+	if config.TimeoutStrategy == "optimistic" {
+		policy = policy.WithTimeoutStrategy(TimeoutOptimistic)
+	}
+	if config.Backoff != nil {
+		policy = policy.WithBackoff(config.Backoff.backoff())
+	}
+	if config.AggregatedErrors {
+		policy = policy.WithAggregatedErrors()
+	}
+	return policy, nil
+}
+
+func (c PolicyConfig) Validate() error {
+	var errs []error
+	invalid := func(field string, reason string) {
+		errs = append(errs, &ConfigError{Field: field, Reason: reason})
+	}
+	if c.RetryLimit != nil && *c.RetryLimit < 0 {
+		invalid("retryLimit", "must not be negative")
+	}
+	if c.RetryForever && c.RetryLimit != nil {
+		invalid("retryForever", "conflicts with retryLimit")
+	}
+	if c.Timeout < 0 {
+		invalid("timeout", "must not be negative")
+	}
+	if c.AttemptTimeout < 0 {
+		invalid("attemptTimeout", "must not be negative")
+	}
+	if c.TimeoutStrategy != "" && c.TimeoutStrategy != "pessimistic" && c.TimeoutStrategy != "optimistic" {
+		invalid("timeoutStrategy", fmt.Sprintf("unknown strategy %q, use pessimistic or optimistic", c.TimeoutStrategy))
+	}
+	if c.Backoff != nil {
+		c.Backoff.validate(invalid)
+	}
+	return errors.Join(errs...)
+}
+
+func (c BackoffConfig) validate(invalid func(field string, reason string)) {
+	if c.Delay <= 0 {
+		invalid("backoff.delay", "must be positive")
+	}
+	if c.MaxDelay < 0 {
+		invalid("backoff.maxDelay", "must not be negative")
+	}
+	switch c.Type {
+	case "constant":
+	case "linear":
+		if c.Increment < 0 {
+			invalid("backoff.increment", "must not be negative")
+		}
+	case "exponential":
+		if c.Multiplier < 1 {
+			invalid("backoff.multiplier", "must be at least 1")
+		}
+	case "decorrelatedJitter":
+		// zero maxDelay means no cap
+		if c.MaxDelay > 0 && c.MaxDelay < c.Delay {
+			invalid("backoff.maxDelay", "must not be less than delay")
+		}
+	default:
+		invalid("backoff.type", fmt.Sprintf("unknown type %q, use constant, linear, exponential or decorrelatedJitter", c.Type))
+	}
+}
+
+func (c BackoffConfig) backoff() Backoff {
+	switch c.Type {
+	case "linear":
+		return NewLinearBackoff(time.Duration(c.Delay), time.Duration(c.Increment))
+	case "exponential":
+		return NewExponentialBackoff(time.Duration(c.Delay), c.Multiplier, time.Duration(c.MaxDelay))
+	case "decorrelatedJitter":
+		return NewDecorrelatedJitterBackoff(time.Duration(c.Delay), time.Duration(c.MaxDelay))
+	}
+	return NewConstantBackoff(time.Duration(c.Delay))
+}
+
+// NewCircuitBreakerFromConfig builds the circuit breaker described by config. It returns every
+// invalid field as ConfigError, joined by errors.Join.
+func NewCircuitBreakerFromConfig(config CircuitBreakerConfig) (CircuitBreaker, error) {
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	breaker := NewCircuitBreaker()
+	if config.ConsecutiveFailureThreshold != nil {
+		breaker = breaker.WithConsecutiveFailureThreshold(*config.ConsecutiveFailureThreshold)
+	}
+	if config.FailureRatio > 0 {
+		breaker = breaker.WithFailureRatioThreshold(config.FailureRatio, time.Duration(config.SamplingWindow), config.MinimumThroughput)
+	}
+	if config.BreakDuration > 0 {
+		breaker = breaker.WithBreakDuration(time.Duration(config.BreakDuration))
+	}
+	return breaker, nil
+}
+
+func (c CircuitBreakerConfig) Validate() error {
+	var errs []error
+	invalid := func(field string, reason string) {
+		errs = append(errs, &ConfigError{Field: field, Reason: reason})
+	}
+	if c.ConsecutiveFailureThreshold != nil && *c.ConsecutiveFailureThreshold < 0 {
+		invalid("consecutiveFailureThreshold", "must not be negative")
+	}
+	if c.FailureRatio < 0 || c.FailureRatio > 1 {
+		invalid("failureRatio", "must be between 0 and 1")
+	}
+	if c.FailureRatio > 0 && c.SamplingWindow <= 0 {
+		invalid("samplingWindow", "must be positive with failureRatio")
+	}
+	if c.MinimumThroughput < 0 {
+		invalid("minimumThroughput", "must not be negative")
+	}
+	if c.BreakDuration < 0 {
+		invalid("breakDuration", "must not be negative")
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,123 @@
+package gotry
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func configErrorFields(err error) []string {
+	var fields []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var configError *ConfigError
+		if errors.As(e, &configError) {
+			fields = append(fields, configError.Field)
+		}
+	}
+	return fields
+}
+
+func TestPolicyConfigFromJSON(t *testing.T) {
+	var config PolicyConfig
+	err := json.Unmarshal([]byte(`{
+		"name": "payment",
+		"retryLimit": 2,
+		"attemptTimeout": "1s",
+		"backoff": {"type": "exponential", "delay": "1ms", "multiplier": 2, "maxDelay": "10ms"}
+	}`), &config)
+	assert.Nil(t, err)
+	assert.Equal(t, Duration(time.Second), config.AttemptTimeout)
+	configured, err := NewPolicyFromConfig(config)
+	assert.Nil(t, err)
+	invoked := 0
+	assert.Equal(t, ExpectedError, configured.TryMethod(countingMethod(&invoked, ExpectedError)))
+	assert.Equal(t, 3, invoked)
+	p := configured.(*policy)
+	assert.Equal(t, "payment", p.name)
+	assert.Equal(t, time.Second, *p.attemptTimeout)
+	assert.Equal(t, 4*time.Millisecond, p.backoff.Delay(2, 2*time.Millisecond))
+}
+
+func TestPolicyConfigFromYAML(t *testing.T) {
+	var config PolicyConfig
+	err := yaml.Unmarshal([]byte(`
+retryForever: true
+letItPanic: true
+timeout: 1m30s
+timeoutStrategy: optimistic
+`), &config)
+	assert.Nil(t, err)
+	configured, err := NewPolicyFromConfig(config)
+	assert.Nil(t, err)
+	p := configured.(*policy)
+	assert.True(t, p.shouldRetry(100))
+	assert.False(t, p.retryOnPanic)
+	assert.Equal(t, 90*time.Second, *p.timeout)
+	assert.Equal(t, TimeoutOptimistic, p.timeoutStrategy)
+}
+
+func TestDurationMarshalsAsText(t *testing.T) {
+	data, err := json.Marshal(PolicyConfig{Timeout: Duration(1500 * time.Millisecond)})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"timeout":"1.5s"}`, string(data))
+	var config PolicyConfig
+	assert.NotNil(t, json.Unmarshal([]byte(`{"timeout": "soon"}`), &config))
+}
+
+func TestDefaultPolicyConfigTriesOnce(t *testing.T) {
+	policy, err := NewPolicyFromConfig(PolicyConfig{})
+	assert.Nil(t, err)
+	invoked := 0
+	_ = policy.TryMethod(countingMethod(&invoked, ExpectedError))
+	assert.Equal(t, 1, invoked)
+}
+
+func TestInvalidPolicyConfigPointsAtFields(t *testing.T) {
+	retryLimit := -1
+	policy, err := NewPolicyFromConfig(PolicyConfig{
+		RetryLimit:      &retryLimit,
+		TimeoutStrategy: "eager",
+		Backoff:         &BackoffConfig{Type: "exponential", Delay: Duration(time.Second), Multiplier: 0.5},
+	})
+	assert.Nil(t, policy)
+	assert.Equal(t, []string{"retryLimit", "timeoutStrategy", "backoff.multiplier"}, configErrorFields(err))
+	assert.Contains(t, err.Error(), "invalid config at backoff.multiplier: must be at least 1")
+}
+
+func TestUnknownBackoffType(t *testing.T) {
+	_, err := NewPolicyFromConfig(PolicyConfig{Backoff: &BackoffConfig{Type: "random"}})
+	assert.Equal(t, []string{"backoff.delay", "backoff.type"}, configErrorFields(err))
+}
+
+func TestDecorrelatedJitterBackoffWithoutMaxDelay(t *testing.T) {
+	var config PolicyConfig
+	assert.Nil(t, json.Unmarshal([]byte(`{"retryLimit": 1, "backoff": {"type": "decorrelatedJitter", "delay": "1ms"}}`), &config))
+	_, err := NewPolicyFromConfig(config)
+	assert.Nil(t, err)
+
+	config.Backoff.MaxDelay = Duration(time.Microsecond)
+	_, err = NewPolicyFromConfig(config)
+	assert.Equal(t, []string{"backoff.maxDelay"}, configErrorFields(err))
+}
+
+func TestCircuitBreakerConfig(t *testing.T) {
+	var config CircuitBreakerConfig
+	assert.Nil(t, yaml.Unmarshal([]byte(`
+consecutiveFailureThreshold: 1
+breakDuration: 1m
+`), &config))
+	breaker, err := NewCircuitBreakerFromConfig(config)
+	assert.Nil(t, err)
+	_ = breaker.TryMethod(errorMethod)
+	assert.Equal(t, CircuitOpen, breaker.State())
+	assert.Equal(t, time.Minute, breaker.(*circuitBreaker).breakDuration)
+}
+
+func TestInvalidCircuitBreakerConfigPointsAtFields(t *testing.T) {
+	_, err := NewCircuitBreakerFromConfig(CircuitBreakerConfig{FailureRatio: 1.5, MinimumThroughput: -1})
+	assert.Equal(t, []string{"failureRatio", "samplingWindow", "minimumThroughput"}, configErrorFields(err))
+}
@@ -0,0 +1,83 @@
+package gotry
+
+import (
+	"context"
+	"testing"
+	"time"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryFuncContextPassesContextToBody(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, ExpectedReturnValue)
+	funcReturn := NewPolicy().WithRetryLimit(1).TryFuncContext(ctx, func(ctx context.Context) FuncReturn {
+		return FuncReturn{ctx.Value(key{}), true, nil}
+	})
+	assert.Nil(t, funcReturn.Err)
+	assert.Equal(t, ExpectedReturnValue, funcReturn.ReturnValue)
+}
+
+func TestCancelContextStopsRetryFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	invoked := 0
+	policy := NewPolicy().WithRetryForever().WithOnFuncRetry(func(int, interface{}, error) {
+		cancel()
+	})
+	funcReturn := policy.TryFuncContext(ctx, func(context.Context) FuncReturn {
+		invoked++
+		return errorFunc()
+	})
+	assert.Equal(t, context.Canceled, funcReturn.Err)
+	assert.Equal(t, ExpectedReturnValue, funcReturn.ReturnValue)
+	assert.Equal(t, 1, invoked)
+}
+
+func TestCancelledContextSkipsFunc(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	invoked := false
+	err := NewPolicy().WithRetryLimit(1).TryMethodContext(ctx, func(context.Context) error {
+		invoked = true
+		return nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.False(t, invoked)
+}
+
+func TestContextDeadlineInterruptsBackoff(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	policy := NewPolicy().WithRetryForever().WithBackoff(NewConstantBackoff(time.Hour))
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- policy.TryMethodContext(ctx, func(context.Context) error {
+			return ExpectedError
+		})
+	}()
+	select {
+	case err := <-errChan:
+		assert.Equal(t, context.DeadlineExceeded, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "context deadline should interrupt backoff")
+	}
+}
+
+func TestTryMethodContextFiresOnMethodRetry(t *testing.T) {
+	mockRetry, onRetryHook := prepareMockExpectingMethodRetry(1)
+	err := NewPolicy().WithRetryLimit(1).WithOnMethodRetry(onRetryHook).
+		TryMethodContext(context.Background(), func(context.Context) error {
+			return ExpectedError
+		})
+	assert.Equal(t, ExpectedError, err)
+	mockRetry.AssertNumberOfCalls(t, OnMethodErrorMethodName, 2)
+}
+
+func TestSuccessIsNotOverriddenByContextError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := NewPolicy().WithRetryLimit(1).TryMethodContext(ctx, func(context.Context) error {
+		cancel()
+		return nil
+	})
+	assert.Nil(t, err)
+}
@@ -0,0 +1,94 @@
+package gotry
+
+type Fallback func(lastReturn FuncReturn) FuncReturn
+type MethodFallback func(err error) error
+type OnFallback func(lastReturn FuncReturn)
+
+// WithFallback supplies a substitute result once the policy has given up, i.e. retries, overall timeout
+// or cancellation are exhausted without success, or a panic escaped the retries.
+func (p policy) WithFallback(fallback Fallback) Policy {
+	p.fallback = fallback
+	return &p
+}
+
+// WithMethodFallback is WithFallback for TryMethod, it gets the last error and returns the substitute one.
+func (p policy) WithMethodFallback(fallback MethodFallback) Policy {
+	p.methodFallback = fallback
+	return &p
+}
+
+// WithFallbackOn restricts fallback to errors matched by predicate. Calling it again widens the set of
+// errors falling back. Invalid return values with nil error always fall back.
+func (p policy) WithFallbackOn(predicate func(err error) bool) Policy {
+	originPredicate := p.fallbackOn
+	if originPredicate != nil {
+		p.fallbackOn = func(err error) bool {
+			return originPredicate(err) || predicate(err)
+		}
+	} else {
+		p.fallbackOn = predicate
+	}
+	return &p
+}
+
+// WithFallbackOnErrors restricts fallback to errors matching one of targets by errors.Is.
+func (p policy) WithFallbackOnErrors(targets ...error) Policy {
+	return p.WithFallbackOn(isAnyOf(targets))
+}
+
+func (p policy) WithOnFallback(onFallback OnFallback) Policy {
+	originEvent := p.onFallback
+	if originEvent != nil {
+		p.onFallback = func(lastReturn FuncReturn) {
+			originEvent(lastReturn)
+			onFallback(lastReturn)
+		}
+	} else {
+		p.onFallback = onFallback
+	}
+	return &p
+}
+
+// tryWithFallback runs execution, which is the whole policy execution, and replaces its failure with
+// the fallback result.
+func (p *policy) tryWithFallback(execution Func) (funcReturn FuncReturn) {
+	if p.fallback == nil {
+		return execution()
+	}
+	panicked := true
+	defer func() {
+		if !panicked {
+			return
+		}
+		panicErr := recover()
+		lastReturn := FuncReturn{Valid: false, Err: newPanicError(panicErr)}
+		if !p.shouldFallback(lastReturn) {
+			panic(panicErr)
+		}
+		funcReturn = p.runFallback(lastReturn)
+	}()
+	funcReturn = execution()
+	panicked = false
+	if !success(funcReturn) && p.shouldFallback(funcReturn) {
+		funcReturn = p.runFallback(funcReturn)
+	}
+	return
+}
+
+func (p *policy) shouldFallback(lastReturn FuncReturn) bool {
+	return lastReturn.Err == nil || p.fallbackOn == nil || p.fallbackOn(lastReturn.Err)
+}
+
+func (p *policy) runFallback(lastReturn FuncReturn) FuncReturn {
+	if p.onFallback != nil {
+		p.onFallback(lastReturn)
+	}
+	return p.fallback(lastReturn)
+}
+
+func (p *policy) wireMethodFallbackToFallback() Policy {
+	methodFallback := p.methodFallback
+	return p.WithFallback(func(lastReturn FuncReturn) FuncReturn {
+		return FuncReturn{nil, true, methodFallback(lastReturn.Err)}
+	})
+}
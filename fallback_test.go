@@ -0,0 +1,103 @@
+package gotry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const FallbackValue = 2
+
+var fallbackFunc Fallback = func(lastReturn FuncReturn) FuncReturn {
+	return FuncReturn{FallbackValue, true, nil}
+}
+
+func TestFallbackAfterRetriesExhausted(t *testing.T) {
+	var lastReturns []FuncReturn
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithFallback(fallbackFunc).WithOnFallback(func(lastReturn FuncReturn) {
+		lastReturns = append(lastReturns, lastReturn)
+	})
+	funcReturn := policy.TryFunc(func() FuncReturn {
+		invoked++
+		return errorFunc()
+	})
+	assert.Equal(t, FuncReturn{FallbackValue, true, nil}, funcReturn)
+	assert.Equal(t, 2, invoked)
+	assert.Equal(t, []FuncReturn{errorFunc()}, lastReturns)
+}
+
+func TestNoFallbackOnSuccess(t *testing.T) {
+	onFallbackFired := false
+	policy := NewPolicy().WithFallback(fallbackFunc).WithOnFallback(func(FuncReturn) {
+		onFallbackFired = true
+	})
+	assert.Equal(t, successFunc(), policy.TryFunc(successFunc))
+	assert.False(t, onFallbackFired)
+}
+
+func TestFallbackOnPanic(t *testing.T) {
+	var lastReturn FuncReturn
+	policy := NewPolicy().WithRetryLimit(1).WithFallback(func(last FuncReturn) FuncReturn {
+		lastReturn = last
+		return fallbackFunc(last)
+	})
+	funcReturn := policy.TryFunc(panicFunc)
+	assert.Equal(t, FallbackValue, funcReturn.ReturnValue)
+	assert.Contains(t, lastReturn.Err.Error(), PanicContent)
+}
+
+func TestFallbackOnTimeout(t *testing.T) {
+	policy := NewPolicy().WithRetryForever().WithTimeout(timeout).
+		WithBackoff(NewConstantBackoff(time.Millisecond)).WithFallbackOnErrors(TimeoutError).WithFallback(fallbackFunc)
+	assert.Equal(t, FallbackValue, policy.TryFunc(errorFunc).ReturnValue)
+}
+
+func TestFallbackRestrictedToErrors(t *testing.T) {
+	policy := NewPolicy().WithFallbackOnErrors(NonTransientError).WithFallback(fallbackFunc)
+	assert.Equal(t, ExpectedError, policy.TryFunc(errorFunc).Err)
+	assert.Panics(t, func() {
+		policy.TryFunc(panicFunc)
+	}, "panic not matched should not fall back")
+	funcReturn := policy.TryFunc(func() FuncReturn {
+		return FuncReturn{nil, true, NonTransientError}
+	})
+	assert.Equal(t, FallbackValue, funcReturn.ReturnValue)
+}
+
+func TestMethodFallback(t *testing.T) {
+	var fallbackErr error
+	onFallbackFired := false
+	policy := NewPolicy().WithRetryLimit(1).
+		WithMethodFallback(func(err error) error {
+			fallbackErr = err
+			return nil
+		}).
+		WithOnFallback(func(FuncReturn) {
+			onFallbackFired = true
+		})
+	assert.Nil(t, policy.TryMethod(errorMethod))
+	assert.Equal(t, ExpectedError, fallbackErr)
+	assert.True(t, onFallbackFired)
+}
+
+func TestFallbackOnContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var fallbackErr error
+	err := NewPolicy().WithMethodFallback(func(err error) error {
+		fallbackErr = err
+		return NonTransientError
+	}).TryMethodContext(ctx, func(context.Context) error {
+		return nil
+	})
+	assert.Equal(t, NonTransientError, err)
+	assert.Equal(t, context.Canceled, fallbackErr)
+}
+
+func TestFallbackAsOutermostLayer(t *testing.T) {
+	wrap := NewPolicyWrap(NewPolicy().WithFallback(fallbackFunc), NewPolicy().WithRetryLimit(2))
+	assert.Equal(t, FallbackValue, wrap.TryFunc(errorFunc).ReturnValue)
+}
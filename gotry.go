@@ -1,6 +1,8 @@
 package gotry
 
 import (
+	"context"
+	"log/slog"
 	"time"
 	"errors"
 )
@@ -8,9 +10,15 @@ import (
 type Func func() FuncReturn
 type OnFuncError func(retriedCount int, returnValue interface{}, err error)
 type Method func() error
+type FuncWithContext func(ctx context.Context) FuncReturn
+type MethodWithContext func(ctx context.Context) error
 type OnMethodError func(retriedCount int, err error)
+// OnPanic gets *PanicError, which holds the recovered value and the stack trace.
 type OnPanic func(panicError interface{})
 type OnTimeout func(timeout time.Duration)
+type OnAttemptTimeout func(retriedCount int, timeout time.Duration)
+// OnAbandoned gets *PanicError, with the stack of the abandoned body, if the body panicked.
+type OnAbandoned func(lateReturn FuncReturn, panicError interface{})
 
 type FuncReturn struct {
 	ReturnValue interface{}
@@ -24,6 +32,9 @@ type Policy interface {
 	WithRetryUntil(stopPredicate func(int) bool) Policy
 	WithLetItPanic() Policy
 	WithTimeout(timeout time.Duration) Policy
+	WithOverallTimeout(timeout time.Duration) Policy
+	WithAttemptTimeout(timeout time.Duration) Policy
+	WithTimeoutStrategy(strategy TimeoutStrategy) Policy
 	WithOnFuncRetry(onRetry OnFuncError) Policy
 	WithOnMethodRetry(onRetry OnMethodError) Policy
 	WithOnPanic(onPanic OnPanic) Policy
@@ -31,26 +42,90 @@ type Policy interface {
 	TryMethod(methodBody Method) error
 	TryFuncWithCancellation(funcBody Func, cancellation Cancellation) FuncReturn
 	TryMethodWithCancellation(methodBody Method, cancellation Cancellation) error
+	TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn
+	TryMethodContext(ctx context.Context, methodBody MethodWithContext) error
 	WithOnTimeout(onTimeout OnTimeout) Policy
+	WithOnAttemptTimeout(onAttemptTimeout OnAttemptTimeout) Policy
+	WithOnAbandoned(onAbandoned OnAbandoned) Policy
+	WithBackoff(backoff Backoff) Policy
+	WithSleepDurationProvider(provider SleepDurationProvider) Policy
+	WithRetryBudget(budget RetryBudget) Policy
+	WithRetryOn(predicate func(err error) bool) Policy
+	WithRetryOnErrors(targets ...error) Policy
+	WithRetryOnErrorType(target interface{}) Policy
+	WithAbortOn(predicate func(err error) bool) Policy
+	WithAbortOnErrors(targets ...error) Policy
+	WithRetryWhen(predicate func(funcReturn FuncReturn) bool) Policy
+	WithSuccessWhen(predicate func(funcReturn FuncReturn) bool) Policy
+	WithFallback(fallback Fallback) Policy
+	WithMethodFallback(fallback MethodFallback) Policy
+	WithFallbackOn(predicate func(err error) bool) Policy
+	WithFallbackOnErrors(targets ...error) Policy
+	WithOnFallback(onFallback OnFallback) Policy
+	WithName(name string) Policy
+	WithOperationKey(operationKey string) Policy
+	WithOnRetryEx(onRetry OnAttemptEvent) Policy
+	WithOnPanicEx(onPanic OnAttemptEvent) Policy
+	WithOnTimeoutEx(onTimeout OnAttemptEvent) Policy
+	WithOnAttemptTimeoutEx(onAttemptTimeout OnAttemptEvent) Policy
+	WithAggregatedErrors() Policy
+	WithOnAttemptDone(onAttemptDone OnAttemptEvent) Policy
+	WithOnExecutionDone(onExecutionDone OnAttemptEvent) Policy
+	WithClock(clock Clock) Policy
+	WithLogger(logger *slog.Logger) Policy
+	WithLogLevels(levels LogLevels) Policy
 }
 
 type policy struct{
-	retryOnPanic  bool
-	timeout       *time.Duration
-	shouldRetry   func(int) bool
-	funcExecutor  func(*policy, Func) FuncReturn
-	onFuncError   OnFuncError
-	onMethodError OnMethodError
-	onPanic       OnPanic
-	onTimeout     OnTimeout
+	retryOnPanic          bool
+	timeout               *time.Duration
+	attemptTimeout        *time.Duration
+	timeoutStrategy       TimeoutStrategy
+	shouldRetry           func(int) bool
+	funcExecutor          func(*policy, FuncWithContext) FuncReturn
+	onFuncError           OnFuncError
+	onMethodError         OnMethodError
+	onPanic               OnPanic
+	onTimeout             OnTimeout
+	onAttemptTimeout      OnAttemptTimeout
+	onAbandoned           OnAbandoned
+	backoff               Backoff
+	sleepDurationProvider SleepDurationProvider
+	retryBudget           RetryBudget
+	retryOn               func(error) bool
+	abortOn               func(error) bool
+	retryWhen             func(FuncReturn) bool
+	successWhen           func(FuncReturn) bool
+	fallback              Fallback
+	methodFallback        MethodFallback
+	fallbackOn            func(error) bool
+	onFallback            OnFallback
+	name                  string
+	operationKey          string
+	onRetryEx             OnAttemptEvent
+	onPanicEx             OnAttemptEvent
+	onTimeoutEx           OnAttemptEvent
+	onAttemptTimeoutEx    OnAttemptEvent
+	onAttemptDone         OnAttemptEvent
+	onExecutionDone       OnAttemptEvent
+	aggregateErrors       bool
+	progress              *attemptProgress
+	clock                 Clock
+	logger                *slog.Logger
+	logLevels             LogLevels
+	cancellation          Cancellation
 }
 
 var TimeoutError = errors.New("timeout")
 
+// NewPolicy returns a policy trying body once without retrying. shouldRetry is asked before every
+// attempt, the first one included.
 func NewPolicy() Policy {
 	policy := policy{
 		retryOnPanic: true,
-		shouldRetry:  func(retriedCount int) bool { return false },
+		shouldRetry:  func(retriedCount int) bool { return retriedCount == 0 },
+		clock:        SystemClock,
+		logLevels:    DefaultLogLevels,
 	}
 	policy.funcExecutor = directTryFunc
 	return &policy
@@ -121,14 +196,8 @@ func (p policy) WithOnPanic(onPanic OnPanic) Policy{
 	return &p
 }
 
-func (p policy) WithTimeout(timeout time.Duration) Policy{
-	p.timeout = &timeout
-	p.funcExecutor = func(policy *policy, funcBody Func) FuncReturn {
-		return policy.tryFuncWithTimeout(funcBody, timeout)
-	}
-	return &p
-}
-
+// WithOnTimeout fires once the overall timeout or an attempt timeout expires, with the expired timeout.
+// WithOnAttemptTimeout also tells which attempt timed out.
 func (p policy) WithOnTimeout(onTimeout OnTimeout) Policy{
 	originEvent := p.onTimeout
 	if originEvent != nil {
@@ -142,24 +211,35 @@ func (p policy) WithOnTimeout(onTimeout OnTimeout) Policy{
 	return &p
 }
 
-func (p *policy) tryFuncWithTimeout(funcBody Func, duration time.Duration) FuncReturn {
-	timeoutCancellation := &cancellation{}
-	funcReturnChan := make(chan FuncReturn)
-	go func() {
-		funcReturnChan <- p.TryFuncWithCancellation(funcBody, timeoutCancellation)
-	}()
-	select {
-	case funcReturn := <-funcReturnChan:
-		{
-			return funcReturn
+func (p policy) WithBackoff(backoff Backoff) Policy{
+	p.backoff = backoff
+	return &p
+}
+
+func (p policy) WithOnAttemptTimeout(onAttemptTimeout OnAttemptTimeout) Policy{
+	originEvent := p.onAttemptTimeout
+	if originEvent != nil {
+		p.onAttemptTimeout = func(retriedCount int, timeout time.Duration) {
+			originEvent(retriedCount, timeout)
+			onAttemptTimeout(retriedCount, timeout)
 		}
-	case <-time.After(duration):
-		{
-			timeoutCancellation.Cancel()
-			notifyOnTimeout(p, duration)
-			return FuncReturn{Valid: false, Err: TimeoutError}
+	} else {
+		p.onAttemptTimeout = onAttemptTimeout
+	}
+	return &p
+}
+
+func (p policy) WithOnAbandoned(onAbandoned OnAbandoned) Policy{
+	originEvent := p.onAbandoned
+	if originEvent != nil {
+		p.onAbandoned = func(lateReturn FuncReturn, panicError interface{}) {
+			originEvent(lateReturn, panicError)
+			onAbandoned(lateReturn, panicError)
 		}
+	} else {
+		p.onAbandoned = onAbandoned
 	}
+	return &p
 }
 
 func notifyOnTimeout(p *policy, duration time.Duration) {
@@ -168,51 +248,122 @@ func notifyOnTimeout(p *policy, duration time.Duration) {
 	}
 }
 
+func (p *policy) notifyOnAttemptTimeout(retried int, timeout time.Duration) {
+	if p.onAttemptTimeout != nil {
+		p.onAttemptTimeout(retried, timeout)
+	}
+}
+
+func (p *policy) notifyOnAbandoned(lateReturn FuncReturn, panicError interface{}) {
+	if p.onAbandoned != nil {
+		p.onAbandoned(lateReturn, panicError)
+	}
+}
+
 func (p *policy) TryFuncWithCancellation(funcBody Func, cancellation Cancellation) FuncReturn{
-	return p.tryFuncWithCancellation(funcBody, cancellation, directTryFunc)
+	return p.tryWithFallback(p.trackingExecution(func(p *policy) FuncReturn {
+		return p.tryFuncWithCancellation(funcBody.ignoreContext(), cancellation, directTryFunc)
+	}))
 }
 
-func (p *policy) tryFuncWithCancellation(funcBody Func,
+func (p *policy) tryFuncWithCancellation(funcBody FuncWithContext,
 										 cancellation Cancellation,
-										 tryExecutor func(*policy, Func) FuncReturn) FuncReturn {
+										 tryExecutor func(*policy, FuncWithContext) FuncReturn) FuncReturn {
 	return tryExecutor(p.withCancellation(cancellation).(*policy), funcBody)
 }
 
 func(p *policy) TryFunc(funcBody Func) (funcReturn FuncReturn) {
+	return p.tryWithFallback(p.trackingExecution(func(p *policy) FuncReturn {
+		return p.tryFunc(funcBody.ignoreContext())
+	}))
+}
+
+// tryFunc passes funcBody a context which is done once the policy's cancellation or a timeout fires.
+func (p *policy) tryFunc(funcBody FuncWithContext) FuncReturn {
 	return p.funcExecutor(p, funcBody)
 }
 
-func directTryFunc(policy *policy, funcBody Func) (funcReturn FuncReturn) {
+func directTryFunc(policy *policy, funcBody FuncWithContext) (funcReturn FuncReturn) {
 	notifyPanic := policy.buildNotifyPanicMethod()
+	funcBody = policy.judgingReturn(funcBody)
+	var delay time.Duration
+	var attempt *AttemptInfo
+	executionStart := policy.clock.Now()
+	policy.depositRetryBudget()
 	for retried := 0; policy.shouldRetry(retried); retried++ {
-		var recoverableBody = policy.wrapFuncBodyWithPanicNotify(notifyPanic, funcBody, retried)
+		if retried > 0 {
+			if !policy.withdrawRetryBudget() {
+				return markRetryBudgetExhausted(funcReturn)
+			}
+			delay = policy.nextDelay(retried-1, delay, funcReturn)
+			attempt.NextDelay = delay
+			policy.progress.fire(func() {
+				policy.notifyOnRetryEx(*attempt)
+			})
+			if !policy.wait(delay) {
+				return
+			}
+		}
+		attempt = policy.startAttempt(retried, executionStart)
+		var attemptBody = policy.withAttemptTimeout(funcBody, retried, attempt)
+		var recoverableBody = policy.wrapFuncBodyWithPanicNotify(notifyPanic, attemptBody, retried, attempt)
 		var panicOccurred bool
 		funcReturn, panicOccurred = recoverableBody()
 		if panicOccurred {
 			continue
 		}
-		if success(funcReturn) {
+		if success(funcReturn) || !policy.isRetryable(funcReturn.Err) {
 			return
 		}
-		policy.onError(retried, funcReturn)
+		policy.progress.fire(func() {
+			policy.onError(retried, funcReturn)
+		})
 	}
 	return
 }
 
-func (p *policy) wrapFuncBodyWithPanicNotify(notifyPanic OnPanic, funcBody Func, retried int)(func() (FuncReturn, bool)) {
+func (p *policy) nextDelay(retriedCount int, lastDelay time.Duration, lastReturn FuncReturn) time.Duration {
+	if p.sleepDurationProvider != nil {
+		if delay := p.sleepDurationProvider(retriedCount, lastReturn); delay > 0 {
+			return delay
+		}
+	}
+	if p.backoff == nil {
+		return 0
+	}
+	return p.backoff.Delay(retriedCount, lastDelay)
+}
+
+// wait sleeps before the next retry and reports false if cancellation was requested meanwhile.
+func (p *policy) wait(delay time.Duration) bool {
+	return waitOrCancel(p.clock, delay, p.cancellation)
+}
+
+func (p *policy) wrapFuncBodyWithPanicNotify(notifyPanic OnPanic, funcBody Func, retried int, attempt *AttemptInfo)(func() (FuncReturn, bool)) {
 	return func() (funcReturn FuncReturn, panicOccurred bool) {
 		panicOccurred = false
 		defer func() {
 			panicErr := recover()
 			if panicErr != nil {
 				panicOccurred = true
-				notifyPanic(panicErr)
+				panicError := recoverPanicError(panicErr)
+				funcReturn = FuncReturn{Valid: false, Err: panicError}
+				p.finishPanickedAttempt(attempt, panicError)
+				p.progress.fire(func() {
+					notifyPanic(panicError)
+					p.notifyOnPanicEx(*attempt)
+					p.notifyOnAttemptDone(*attempt)
+				})
 				panicIfExceedLimit(p,
 					nextIterationBecauseDeferExecuteAtLastSoIShouldIncreaseToJudgeIfPanicNeeded(retried),
-					panicErr)
+					panicError.Value)
 			}
 		}()
 		funcReturn = funcBody()
+		p.finishAttempt(attempt, funcReturn)
+		p.progress.fire(func() {
+			p.notifyOnAttemptDone(*attempt)
+		})
 		return
 	}
 }
@@ -245,13 +396,39 @@ func (p *policy) TryMethodWithCancellation(methodBody Method, cancellation Cance
 
 func(p *policy) TryMethod(methodBody Method) error {
 	function := methodBody.convertToFunc()
-	var wrappedPolicy Policy = p
+	var funcReturn = p.methodPolicy().TryFunc(function)
+	return funcReturn.Err
+}
+
+// TryFuncContext stops retrying once ctx is done and returns ctx.Err() if funcBody has not succeeded by then.
+// funcBody gets a context derived from ctx, which is also done once a timeout of the policy expires,
+// so the work itself can be aborted.
+func (p *policy) TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn {
+	return p.tryWithFallback(p.trackingExecution(func(p *policy) FuncReturn {
+		ctxCancellation := newContextCancellation(ctx)
+		// unlinks ctxCancellation from ctx once we are done
+		defer ctxCancellation.Cancel()
+		funcReturn := p.withCancellation(ctxCancellation).(*policy).tryFunc(funcBody)
+		if !success(funcReturn) && ctx.Err() != nil {
+			funcReturn.Err = ctx.Err()
+		}
+		return funcReturn
+	}))
+}
+
+func (p *policy) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	return p.methodPolicy().TryFuncContext(ctx, methodBody.convertToFunc()).Err
+}
+
+func (p *policy) methodPolicy() Policy {
+	var methodPolicy Policy = p
 	if p.onMethodError != nil {
-		wrappedPolicy = p.wireOnFuncErrorToOnMethodError()
+		methodPolicy = methodPolicy.(*policy).wireOnFuncErrorToOnMethodError()
 	}
-
-	var funcReturn = wrappedPolicy.TryFunc(function)
-	return funcReturn.Err
+	if p.methodFallback != nil {
+		methodPolicy = methodPolicy.(*policy).wireMethodFallbackToFallback()
+	}
+	return methodPolicy
 }
 
 func (p *policy) wireOnFuncErrorToOnMethodError() Policy {
@@ -267,6 +444,19 @@ func (methodBody Method) convertToFunc() Func{
 	}
 }
 
+func (funcBody Func) ignoreContext() FuncWithContext {
+	return func(context.Context) FuncReturn {
+		return funcBody()
+	}
+}
+
+func (methodBody MethodWithContext) convertToFunc() FuncWithContext {
+	return func(ctx context.Context) FuncReturn {
+		var err = methodBody(ctx)
+		return FuncReturn{nil, true, err}
+	}
+}
+
 func panicIfExceedLimit(policy *policy, i int, err interface{}) {
 	if !(policy.retryOnPanic && policy.shouldRetry(i)) {
 		panic(err)
@@ -275,6 +465,7 @@ func panicIfExceedLimit(policy *policy, i int, err interface{}) {
 
 func (p policy) withCancellation(cancellation Cancellation) Policy{
 	shouldRetry := p.shouldRetry
+	p.cancellation = cancellation
 	return p.WithRetryUntil(func(retriedCount int) bool {
 		return cancellation.IsCancellationRequested() || !shouldRetry(retriedCount)
 	})
@@ -0,0 +1,142 @@
+// Package gotrytest helps testing code built on gotry.
+package gotrytest
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lonegunmanb/gotry"
+)
+
+// FakeClock is a gotry.Clock whose time only moves when Advance is called, so timeouts and delays
+// expire exactly when a test wants them to.
+type FakeClock struct {
+	mutex   sync.Mutex
+	now     time.Time
+	timers  []*fakeTimer
+	changed chan struct{}
+}
+
+// NewFakeClock returns a FakeClock starting at now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, changed: make(chan struct{})}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.now
+}
+
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	return c.NewTimer(d).C()
+}
+
+// Sleep blocks until the clock has been advanced by d.
+func (c *FakeClock) Sleep(d time.Duration) {
+	<-c.After(d)
+}
+
+func (c *FakeClock) NewTimer(d time.Duration) gotry.Timer {
+	timer := &fakeTimer{clock: c, c: make(chan time.Time, 1)}
+	timer.Reset(d)
+	return timer
+}
+
+// Advance moves the clock forward by d, firing the timers expiring meanwhile in order.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+	for len(c.timers) > 0 && !c.timers[0].deadline.After(c.now) {
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		timer.fire()
+	}
+	c.notifyChanged()
+}
+
+// Timers tells how many timers are waiting to fire.
+func (c *FakeClock) Timers() int {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return len(c.timers)
+}
+
+// BlockUntil waits until at least n timers are waiting to fire, i.e. until the code under test
+// running in another goroutine has started to wait for the clock.
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mutex.Lock()
+		waiting, changed := len(c.timers), c.changed
+		c.mutex.Unlock()
+		if waiting >= n {
+			return
+		}
+		<-changed
+	}
+}
+
+// notifyChanged wakes up BlockUntil, the caller holds the mutex.
+func (c *FakeClock) notifyChanged() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *FakeClock) add(timer *fakeTimer) {
+	c.timers = append(c.timers, timer)
+	c.notifyChanged()
+}
+
+// remove reports whether timer was waiting to fire, the caller holds the mutex.
+func (c *FakeClock) remove(timer *fakeTimer) bool {
+	for i, t := range c.timers {
+		if t == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.notifyChanged()
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock    *FakeClock
+	deadline time.Time
+	c        chan time.Time
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	return t.clock.remove(t)
+}
+
+func (t *fakeTimer) Reset(d time.Duration) bool {
+	t.clock.mutex.Lock()
+	defer t.clock.mutex.Unlock()
+	active := t.clock.remove(t)
+	t.deadline = t.clock.now.Add(d)
+	if d <= 0 {
+		t.fire()
+	} else {
+		t.clock.add(t)
+	}
+	return active
+}
+
+// fire sends the time without blocking like time.Timer does, the caller holds the mutex.
+func (t *fakeTimer) fire() {
+	select {
+	case t.c <- t.clock.now:
+	default:
+	}
+}
@@ -0,0 +1,109 @@
+package gotrytest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/lonegunmanb/gotry"
+	"github.com/stretchr/testify/assert"
+)
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+var errExpected = errors.New("expected")
+
+func TestTimerFiresOnlyOnceAdvancedPastDeadline(t *testing.T) {
+	clock := NewFakeClock(start)
+	timer := clock.NewTimer(time.Second)
+	clock.Advance(time.Second - 1)
+	select {
+	case <-timer.C():
+		assert.Fail(t, "timer should not fire before deadline")
+	default:
+	}
+	clock.Advance(1)
+	assert.Equal(t, start.Add(time.Second), <-timer.C())
+	assert.Equal(t, 0, clock.Timers())
+}
+
+func TestStopAndResetTimer(t *testing.T) {
+	clock := NewFakeClock(start)
+	timer := clock.NewTimer(time.Second)
+	assert.True(t, timer.Stop())
+	assert.False(t, timer.Stop())
+	assert.False(t, timer.Reset(time.Minute))
+	clock.Advance(time.Second)
+	assert.Equal(t, 1, clock.Timers())
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Second+time.Minute), <-timer.C())
+}
+
+func TestSleepBlocksUntilAdvanced(t *testing.T) {
+	clock := NewFakeClock(start)
+	woke := make(chan struct{})
+	go func() {
+		clock.Sleep(time.Hour)
+		close(woke)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	<-woke
+	assert.Equal(t, start.Add(time.Hour), clock.Now())
+}
+
+func TestOverallTimeoutExpiresOnAdvance(t *testing.T) {
+	clock := NewFakeClock(start)
+	policy := gotry.NewPolicy().WithOverallTimeout(time.Minute).WithClock(clock)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- policy.TryMethodContext(context.Background(), func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Minute)
+	assert.Equal(t, gotry.TimeoutError, <-errChan)
+}
+
+func TestBackoffWaitsForClock(t *testing.T) {
+	clock := NewFakeClock(start)
+	invoked := make(chan struct{}, 2)
+	policy := gotry.NewPolicy().WithRetryLimit(1).WithBackoff(gotry.NewConstantBackoff(time.Hour)).WithClock(clock)
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- policy.TryMethod(func() error {
+			invoked <- struct{}{}
+			return errExpected
+		})
+	}()
+	clock.BlockUntil(1)
+	assert.Equal(t, 1, len(invoked))
+	clock.Advance(time.Hour)
+	assert.Equal(t, errExpected, <-errChan)
+	assert.Equal(t, 2, len(invoked))
+}
+
+func TestCircuitBreakerHalfOpensOnAdvance(t *testing.T) {
+	clock := NewFakeClock(start)
+	breaker := gotry.NewCircuitBreaker().WithConsecutiveFailureThreshold(1).WithBreakDuration(time.Minute).WithClock(clock)
+	breaker.TryMethod(func() error {
+		return errExpected
+	})
+	assert.Equal(t, gotry.CircuitOpen, breaker.State())
+	clock.Advance(time.Minute)
+	assert.Equal(t, gotry.CircuitHalfOpen, breaker.State())
+}
+
+func TestRetryAfterDateReadsClock(t *testing.T) {
+	clock := NewFakeClock(start)
+	response := &http.Response{Header: http.Header{}}
+	response.Header.Set("Retry-After", start.Add(30*time.Second).Format(http.TimeFormat))
+	provider := gotry.RetryAfterDurationWithClock(clock)
+	funcReturn := gotry.FuncReturn{ReturnValue: response}
+	assert.Equal(t, 30*time.Second, provider(0, funcReturn))
+	clock.Advance(10 * time.Second)
+	assert.Equal(t, 20*time.Second, provider(0, funcReturn))
+}
@@ -0,0 +1,213 @@
+package gotry
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+type OnHedge func(hedgedCount int)
+
+// Hedging fires another attempt when the previous ones neither succeeded nor failed within hedge delay,
+// returns whichever succeeds first and cancels the context of the others. Only hedge idempotent work.
+// Hedgings derived from one another by With... share the observed latencies.
+type Hedging interface {
+	WithMaxHedgedAttempts(maxHedgedAttempts int) Hedging
+	WithHedgeDelay(hedgeDelay time.Duration) Hedging
+	WithPercentileHedgeDelay(percentile float64) Hedging
+	WithOnHedge(onHedge OnHedge) Hedging
+	WithClock(clock Clock) Hedging
+	TryFunc(funcBody Func) FuncReturn
+	TryMethod(methodBody Method) error
+	TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn
+	TryMethodContext(ctx context.Context, methodBody MethodWithContext) error
+}
+
+const latencySampleSize = 100
+const minimumLatencySamples = 10
+
+type hedging struct {
+	maxHedgedAttempts int
+	hedgeDelay        time.Duration
+	percentile        float64
+	onHedge           OnHedge
+	clock             Clock
+	latencies         *latencyRecorder
+}
+
+type latencyRecorder struct {
+	mutex   sync.Mutex
+	samples []time.Duration
+	next    int
+}
+
+// NewHedging fires up to maxHedgedAttempts extra attempts, one every hedgeDelay.
+func NewHedging(maxHedgedAttempts int, hedgeDelay time.Duration) Hedging {
+	checkMaxHedgedAttempts(maxHedgedAttempts)
+	return &hedging{
+		maxHedgedAttempts: maxHedgedAttempts,
+		hedgeDelay:        hedgeDelay,
+		clock:             SystemClock,
+		latencies:         &latencyRecorder{},
+	}
+}
+
+func (h hedging) WithMaxHedgedAttempts(maxHedgedAttempts int) Hedging {
+	checkMaxHedgedAttempts(maxHedgedAttempts)
+	h.maxHedgedAttempts = maxHedgedAttempts
+	return &h
+}
+
+func checkMaxHedgedAttempts(maxHedgedAttempts int) {
+	if maxHedgedAttempts < 0 {
+		panic("gotry: hedging needs non-negative max hedged attempts")
+	}
+}
+
+func (h hedging) WithHedgeDelay(hedgeDelay time.Duration) Hedging {
+	h.hedgeDelay = hedgeDelay
+	return &h
+}
+
+// WithPercentileHedgeDelay computes hedge delay as the given percentile (0 to 1) of the latencies of
+// the last 100 successful attempts. The static hedge delay is used until 10 latencies are observed.
+func (h hedging) WithPercentileHedgeDelay(percentile float64) Hedging {
+	h.percentile = percentile
+	return &h
+}
+
+func (h hedging) WithOnHedge(onHedge OnHedge) Hedging {
+	originEvent := h.onHedge
+	if originEvent != nil {
+		h.onHedge = func(hedgedCount int) {
+			originEvent(hedgedCount)
+			onHedge(hedgedCount)
+		}
+	} else {
+		h.onHedge = onHedge
+	}
+	return &h
+}
+
+func (h hedging) WithClock(clock Clock) Hedging {
+	h.clock = clockOrSystem(clock)
+	return &h
+}
+
+func (h *hedging) TryFunc(funcBody Func) FuncReturn {
+	return h.TryFuncContext(context.Background(), funcBody.ignoreContext())
+}
+
+func (h *hedging) TryMethod(methodBody Method) error {
+	return h.TryFunc(methodBody.convertToFunc()).Err
+}
+
+func (h *hedging) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	return h.TryFuncContext(ctx, methodBody.convertToFunc()).Err
+}
+
+// TryFuncContext returns the first successful attempt, or the last failed one once every attempt failed.
+// A panic is raised again in the caller only if the returned attempt panicked.
+func (h *hedging) TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn {
+	hedgeCtx, cancel := context.WithCancel(ctx)
+	// cancels the losers
+	defer cancel()
+	resultChan := make(chan bodyResult, h.maxHedgedAttempts+1)
+	launch := func() {
+		go func() {
+			result := bodyResult{panicked: true}
+			start := h.clock.Now()
+			defer func() {
+				if result.panicked {
+					panicErr := recover()
+					result.panicErr = panicErr
+					result.funcReturn = FuncReturn{Valid: false, Err: newPanicError(panicErr)}
+				} else if success(result.funcReturn) {
+					h.latencies.record(h.clock.Now().Sub(start))
+				}
+				resultChan <- result
+			}()
+			result.funcReturn = funcBody(hedgeCtx)
+			result.panicked = false
+		}()
+	}
+	launch()
+	launched, returned := 1, 0
+	hedgeTimer := h.clock.NewTimer(h.delay())
+	defer hedgeTimer.Stop()
+	var last bodyResult
+	for {
+		select {
+		case last = <-resultChan:
+			returned++
+			if success(last.funcReturn) {
+				return last.funcReturn
+			}
+			if launched <= h.maxHedgedAttempts {
+				// no point waiting for hedge delay once an attempt has failed
+				launched++
+				h.notifyOnHedge(launched - 1)
+				launch()
+			} else if returned == launched {
+				return last.unwrap()
+			}
+		case <-hedgeTimer.C():
+			if launched <= h.maxHedgedAttempts {
+				launched++
+				h.notifyOnHedge(launched - 1)
+				launch()
+				hedgeTimer.Reset(h.delay())
+			}
+		case <-ctx.Done():
+			return FuncReturn{ReturnValue: last.funcReturn.ReturnValue, Valid: false, Err: ctx.Err()}
+		}
+	}
+}
+
+func (h *hedging) delay() time.Duration {
+	if h.percentile > 0 {
+		if delay, ok := h.latencies.percentile(h.percentile); ok {
+			return delay
+		}
+	}
+	return h.hedgeDelay
+}
+
+func (h *hedging) notifyOnHedge(hedgedCount int) {
+	if h.onHedge != nil {
+		h.onHedge(hedgedCount)
+	}
+}
+
+func (r *latencyRecorder) record(latency time.Duration) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.samples) < latencySampleSize {
+		r.samples = append(r.samples, latency)
+		return
+	}
+	r.samples[r.next] = latency
+	r.next = (r.next + 1) % latencySampleSize
+}
+
+func (r *latencyRecorder) percentile(percentile float64) (time.Duration, bool) {
+	r.mutex.Lock()
+	samples := append([]time.Duration(nil), r.samples...)
+	r.mutex.Unlock()
+	if len(samples) < minimumLatencySamples {
+		return 0, false
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i] < samples[j]
+	})
+	index := int(math.Ceil(percentile*float64(len(samples)))) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(samples) {
+		index = len(samples) - 1
+	}
+	return samples[index], true
+}
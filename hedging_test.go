@@ -0,0 +1,110 @@
+package gotry
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHedgingReturnsFastAttempt(t *testing.T) {
+	var invoked int32
+	var hedged []int
+	loserCancelled := make(chan struct{})
+	hedging := NewHedging(1, timeout).WithOnHedge(func(hedgedCount int) {
+		hedged = append(hedged, hedgedCount)
+	})
+	funcReturn := hedging.TryFuncContext(context.Background(), func(ctx context.Context) FuncReturn {
+		if atomic.AddInt32(&invoked, 1) == 1 {
+			<-ctx.Done()
+			close(loserCancelled)
+			return FuncReturn{nil, false, ctx.Err()}
+		}
+		return successFunc()
+	})
+	assert.Equal(t, ExpectedReturnValue, funcReturn.ReturnValue)
+	assert.Equal(t, []int{1}, hedged)
+	select {
+	case <-loserCancelled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "loser should be cancelled")
+	}
+}
+
+func TestHedgingDoesNotHedgeFastSuccess(t *testing.T) {
+	hedged := false
+	hedging := NewHedging(2, time.Second).WithOnHedge(func(int) {
+		hedged = true
+	})
+	assert.Nil(t, hedging.TryMethod(successMethod))
+	assert.False(t, hedged)
+}
+
+func TestHedgingHedgesImmediatelyOnFailure(t *testing.T) {
+	var invoked int32
+	hedging := NewHedging(2, time.Hour)
+	funcReturn := hedging.TryFunc(func() FuncReturn {
+		if atomic.AddInt32(&invoked, 1) < 3 {
+			return errorFunc()
+		}
+		return successFunc()
+	})
+	assert.Nil(t, funcReturn.Err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&invoked))
+}
+
+func TestHedgingReturnsLastFailure(t *testing.T) {
+	var invoked int32
+	err := NewHedging(2, time.Hour).TryMethod(func() error {
+		atomic.AddInt32(&invoked, 1)
+		return ExpectedError
+	})
+	assert.Equal(t, ExpectedError, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&invoked))
+}
+
+func TestHedgingRaisesPanicOfLastAttempt(t *testing.T) {
+	assert.PanicsWithValue(t, PanicContent, func() {
+		NewHedging(1, time.Hour).TryFunc(panicFunc)
+	})
+}
+
+func TestHedgingStopsOnContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	err := NewHedging(1, time.Hour).TryMethodContext(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestPercentileHedgeDelay(t *testing.T) {
+	recorder := &latencyRecorder{}
+	_, ok := recorder.percentile(0.9)
+	assert.False(t, ok, "percentile should not be used before enough samples")
+	for i := 1; i <= latencySampleSize+10; i++ {
+		recorder.record(time.Duration(i) * time.Millisecond)
+	}
+	delay, ok := recorder.percentile(0.9)
+	assert.True(t, ok)
+	assert.Equal(t, 100*time.Millisecond, delay)
+	hedging := NewHedging(1, time.Hour).WithPercentileHedgeDelay(0.5).(*hedging)
+	assert.Equal(t, time.Hour, hedging.delay())
+	hedging.latencies = recorder
+	assert.Equal(t, 60*time.Millisecond, hedging.delay())
+}
+
+func TestInvalidHedging(t *testing.T) {
+	assert.Panics(t, func() {
+		NewHedging(-1, time.Millisecond)
+	})
+	assert.Panics(t, func() {
+		NewHedging(1, time.Millisecond).WithMaxHedgedAttempts(-1)
+	})
+	assert.NotPanics(t, func() {
+		NewHedging(0, time.Millisecond)
+	})
+}
@@ -0,0 +1,79 @@
+package gotry
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LogLevels are the levels of the records emitted by WithLogger.
+type LogLevels struct {
+	Retry   slog.Level
+	Panic   slog.Level
+	Timeout slog.Level
+	// Failure is the level of an execution that failed for good.
+	Failure slog.Level
+	// Recovery is the level of an execution that succeeded after retries, success at the first
+	// attempt is not logged.
+	Recovery slog.Level
+}
+
+var DefaultLogLevels = LogLevels{
+	Retry:    slog.LevelWarn,
+	Panic:    slog.LevelError,
+	Timeout:  slog.LevelWarn,
+	Failure:  slog.LevelError,
+	Recovery: slog.LevelInfo,
+}
+
+// WithLogger emits a structured record for every retry, panic, timeout, final failure and success
+// after retries, at DefaultLogLevels unless WithLogLevels says otherwise. A nil logger logs nothing.
+func (p policy) WithLogger(logger *slog.Logger) Policy {
+	p.logger = logger
+	return &p
+}
+
+func (p policy) WithLogLevels(levels LogLevels) Policy {
+	p.logLevels = levels
+	return &p
+}
+
+func (p *policy) logRetry(info AttemptInfo) {
+	p.log(p.logLevels.Retry, "retrying", info, slog.Duration("delay", info.NextDelay))
+}
+
+func (p *policy) logPanic(info AttemptInfo) {
+	p.log(p.logLevels.Panic, "attempt panicked", info, slog.Any("panic", info.PanicValue),
+		slog.String("stack", string(info.Stack)))
+}
+
+func (p *policy) logAttemptTimeout(info AttemptInfo) {
+	p.log(p.logLevels.Timeout, "attempt timed out", info)
+}
+
+func (p *policy) logTimeout(info AttemptInfo) {
+	p.log(p.logLevels.Timeout, "execution timed out", info)
+}
+
+func (p *policy) logExecutionDone(info AttemptInfo) {
+	switch {
+	case !info.Succeeded:
+		p.log(p.logLevels.Failure, "execution failed", info)
+	case info.Attempt > 1:
+		p.log(p.logLevels.Recovery, "execution succeeded after retries", info)
+	}
+}
+
+func (p *policy) log(level slog.Level, message string, info AttemptInfo, attrs ...slog.Attr) {
+	if p.logger == nil || !p.logger.Enabled(context.Background(), level) {
+		return
+	}
+	attrs = append([]slog.Attr{
+		slog.String("policy", info.PolicyName),
+		slog.String("operation", info.OperationKey),
+		slog.Int("attempt", info.Attempt),
+		slog.Duration("elapsed", info.Elapsed),
+		slog.Duration("total_elapsed", info.TotalElapsed),
+		slog.Any("error", info.LastError),
+	}, attrs...)
+	p.logger.LogAttrs(context.Background(), level, message, attrs...)
+}
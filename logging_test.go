@@ -0,0 +1,94 @@
+package gotry
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newRecordingLogger() (*slog.Logger, func() []map[string]interface{}) {
+	buffer := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buffer, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return logger, func() []map[string]interface{} {
+		var records []map[string]interface{}
+		for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			record := map[string]interface{}{}
+			_ = json.Unmarshal([]byte(line), &record)
+			records = append(records, record)
+		}
+		return records
+	}
+}
+
+func TestLoggerRecordsRetriesAndRecovery(t *testing.T) {
+	logger, records := newRecordingLogger()
+	invoked := 0
+	err := NewPolicy().WithRetryLimit(2).WithName("payment").WithLogger(logger).TryMethod(func() error {
+		invoked++
+		if invoked < 3 {
+			return ExpectedError
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	logged := records()
+	assert.Equal(t, 3, len(logged))
+	for i, record := range logged[:2] {
+		assert.Equal(t, "retrying", record["msg"])
+		assert.Equal(t, "WARN", record["level"])
+		assert.Equal(t, float64(i+1), record["attempt"])
+		assert.Equal(t, "payment", record["policy"])
+		assert.Equal(t, ExpectedError.Error(), record["error"])
+	}
+	assert.Equal(t, "execution succeeded after retries", logged[2]["msg"])
+	assert.Equal(t, "INFO", logged[2]["level"])
+	assert.Equal(t, float64(3), logged[2]["attempt"])
+}
+
+func TestLoggerRecordsPanicAndFailure(t *testing.T) {
+	logger, records := newRecordingLogger()
+	policy := NewPolicy().WithLogger(logger).WithLogLevels(LogLevels{
+		Retry:    slog.LevelDebug,
+		Panic:    slog.LevelWarn,
+		Timeout:  slog.LevelDebug,
+		Failure:  slog.LevelWarn,
+		Recovery: slog.LevelDebug,
+	})
+	assert.Panics(t, func() {
+		policy.TryMethod(panicMethod)
+	})
+	logged := records()
+	assert.Equal(t, 2, len(logged))
+	assert.Equal(t, "attempt panicked", logged[0]["msg"])
+	assert.Equal(t, "WARN", logged[0]["level"])
+	assert.Equal(t, PanicContent, logged[0]["panic"])
+	assert.Contains(t, logged[0]["stack"], "retrytestbase.go")
+	assert.Equal(t, "execution failed", logged[1]["msg"])
+	assert.Equal(t, "WARN", logged[1]["level"])
+}
+
+func TestLoggerRecordsTimeouts(t *testing.T) {
+	logger, records := newRecordingLogger()
+	var invoked int32
+	funcReturn := NewPolicy().WithRetryLimit(1).WithAttemptTimeout(timeout).WithLogger(logger).
+		TryFunc(slowThenSuccessFunc(&invoked, 2))
+	assert.Equal(t, TimeoutError, funcReturn.Err)
+	var messages []interface{}
+	for _, record := range records() {
+		messages = append(messages, record["msg"])
+	}
+	assert.Equal(t, []interface{}{"attempt timed out", "retrying", "attempt timed out", "execution failed"}, messages)
+}
+
+func TestSuccessAtFirstAttemptIsNotLogged(t *testing.T) {
+	logger, records := newRecordingLogger()
+	assert.Nil(t, NewPolicy().WithRetryLimit(1).WithLogger(logger).TryMethod(successMethod))
+	assert.Empty(t, records())
+}
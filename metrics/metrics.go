@@ -0,0 +1,124 @@
+// Package metrics exports what policies and circuit breakers do as Prometheus metrics.
+package metrics
+
+import (
+	"math"
+	"time"
+
+	"github.com/lonegunmanb/gotry"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const policyLabel = "policy"
+
+// Collector records the events of the policies and circuit breakers it instruments, every metric is
+// labelled by the name given to Instrument or InstrumentCircuitBreaker. Register it to a
+// prometheus.Registerer to have it scraped.
+type Collector struct {
+	attempts           *prometheus.CounterVec
+	retries            *prometheus.CounterVec
+	successes          *prometheus.CounterVec
+	failures           *prometheus.CounterVec
+	panics             *prometheus.CounterVec
+	timeouts           *prometheus.CounterVec
+	circuitTransitions *prometheus.CounterVec
+	attemptDuration    *prometheus.HistogramVec
+	executionDuration  *prometheus.HistogramVec
+}
+
+// NewCollector names every metric gotry_..., or namespace_gotry_... for a non-empty namespace.
+func NewCollector(namespace string) *Collector {
+	counter := func(name string, help string, labels ...string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "gotry",
+			Name:      name,
+			Help:      help,
+		}, append([]string{policyLabel}, labels...))
+	}
+	histogram := func(name string, help string) *prometheus.HistogramVec {
+		return prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "gotry",
+			Name:      name,
+			Help:      help,
+			Buckets:   prometheus.DefBuckets,
+		}, []string{policyLabel})
+	}
+	return &Collector{
+		attempts:           counter("attempts_total", "Attempts made, first attempts and retries."),
+		retries:            counter("retries_total", "Retries made after a failed attempt."),
+		successes:          counter("successes_total", "Executions which succeeded."),
+		failures:           counter("failures_total", "Executions which failed, including those ended by a panic."),
+		panics:             counter("panics_total", "Attempts which panicked."),
+		timeouts:           counter("timeouts_total", "Timeouts by kind, attempt or overall.", "kind"),
+		circuitTransitions: counter("circuit_transitions_total", "Circuit state transitions by the state entered.", "state"),
+		attemptDuration:    histogram("attempt_duration_seconds", "How long attempts took."),
+		executionDuration:  histogram("execution_duration_seconds", "How long executions took, retries and delays included."),
+	}
+}
+
+func (c *Collector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{c.attempts, c.retries, c.successes, c.failures, c.panics, c.timeouts,
+		c.circuitTransitions, c.attemptDuration, c.executionDuration}
+}
+
+func (c *Collector) Describe(descs chan<- *prometheus.Desc) {
+	for _, collector := range c.collectors() {
+		collector.Describe(descs)
+	}
+}
+
+func (c *Collector) Collect(metrics chan<- prometheus.Metric) {
+	for _, collector := range c.collectors() {
+		collector.Collect(metrics)
+	}
+}
+
+// Instrument names policy by name, and returns a policy recording its events under that name.
+func (c *Collector) Instrument(name string, policy gotry.Policy) gotry.Policy {
+	return policy.WithName(name).
+		WithOnAttemptDone(func(info gotry.AttemptInfo) {
+			c.attempts.WithLabelValues(name).Inc()
+			c.attemptDuration.WithLabelValues(name).Observe(info.Elapsed.Seconds())
+			if info.PanicValue != nil {
+				c.panics.WithLabelValues(name).Inc()
+			}
+		}).
+		WithOnRetryEx(func(gotry.AttemptInfo) {
+			c.retries.WithLabelValues(name).Inc()
+		}).
+		WithOnAttemptTimeoutEx(func(gotry.AttemptInfo) {
+			c.timeouts.WithLabelValues(name, "attempt").Inc()
+		}).
+		WithOnTimeoutEx(func(gotry.AttemptInfo) {
+			c.timeouts.WithLabelValues(name, "overall").Inc()
+		}).
+		WithOnExecutionDone(func(info gotry.AttemptInfo) {
+			if info.Succeeded {
+				c.successes.WithLabelValues(name).Inc()
+			} else {
+				c.failures.WithLabelValues(name).Inc()
+			}
+			c.executionDuration.WithLabelValues(name).Observe(info.TotalElapsed.Seconds())
+		})
+}
+
+// InstrumentCircuitBreaker returns a circuit breaker counting the state transitions of breaker under name.
+func (c *Collector) InstrumentCircuitBreaker(name string, breaker gotry.CircuitBreaker) gotry.CircuitBreaker {
+	return breaker.
+		WithOnBreak(func(_ error, breakDuration time.Duration) {
+			// the state may have moved on already, e.g. to half-open with a zero break duration
+			state := gotry.CircuitOpen
+			if breakDuration == time.Duration(math.MaxInt64) {
+				state = gotry.CircuitIsolated
+			}
+			c.circuitTransitions.WithLabelValues(name, state.String()).Inc()
+		}).
+		WithOnHalfOpen(func() {
+			c.circuitTransitions.WithLabelValues(name, gotry.CircuitHalfOpen.String()).Inc()
+		}).
+		WithOnReset(func() {
+			c.circuitTransitions.WithLabelValues(name, gotry.CircuitClosed.String()).Inc()
+		})
+}
@@ -0,0 +1,104 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lonegunmanb/gotry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+var errExpected = errors.New("expected")
+
+func newRegisteredCollector(t *testing.T) (*Collector, *prometheus.Registry) {
+	collector := NewCollector("test")
+	registry := prometheus.NewRegistry()
+	assert.Nil(t, registry.Register(collector))
+	return collector, registry
+}
+
+func TestInstrumentCountsAttemptsAndExecutions(t *testing.T) {
+	collector, registry := newRegisteredCollector(t)
+	policy := collector.Instrument("payment", gotry.NewPolicy().WithRetryLimit(2))
+	invoked := 0
+	assert.Nil(t, policy.TryMethod(func() error {
+		invoked++
+		if invoked < 3 {
+			return errExpected
+		}
+		return nil
+	}))
+	assert.Equal(t, errExpected, policy.TryMethod(func() error {
+		return errExpected
+	}))
+	assert.Equal(t, float64(6), testutil.ToFloat64(collector.attempts.WithLabelValues("payment")))
+	assert.Equal(t, float64(4), testutil.ToFloat64(collector.retries.WithLabelValues("payment")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.successes.WithLabelValues("payment")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.failures.WithLabelValues("payment")))
+	count, err := testutil.GatherAndCount(registry, "test_gotry_execution_duration_seconds")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, count)
+	problems, err := testutil.GatherAndLint(registry)
+	assert.Nil(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestInstrumentCountsPanicsAndTimeouts(t *testing.T) {
+	collector, _ := newRegisteredCollector(t)
+	policy := collector.Instrument("slow", gotry.NewPolicy().WithRetryLimit(1).WithAttemptTimeout(time.Millisecond))
+	assert.Equal(t, gotry.TimeoutError, policy.TryMethod(func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}))
+	assert.Equal(t, float64(2), testutil.ToFloat64(collector.timeouts.WithLabelValues("slow", "attempt")))
+	assert.Panics(t, func() {
+		collector.Instrument("panic", gotry.NewPolicy()).TryMethod(func() error {
+			panic("boom")
+		})
+	})
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.panics.WithLabelValues("panic")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.failures.WithLabelValues("panic")))
+}
+
+func TestInstrumentCountsOverallTimeout(t *testing.T) {
+	collector, _ := newRegisteredCollector(t)
+	policy := collector.Instrument("overall", gotry.NewPolicy().WithOverallTimeout(time.Millisecond))
+	assert.Equal(t, gotry.TimeoutError, policy.TryMethod(func() error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.timeouts.WithLabelValues("overall", "overall")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.failures.WithLabelValues("overall")))
+}
+
+func TestInstrumentCircuitBreakerCountsTransitions(t *testing.T) {
+	collector, _ := newRegisteredCollector(t)
+	breaker := collector.InstrumentCircuitBreaker("db", gotry.NewCircuitBreaker().
+		WithConsecutiveFailureThreshold(1).WithBreakDuration(time.Millisecond))
+	_ = breaker.TryMethod(func() error {
+		return errExpected
+	})
+	time.Sleep(2 * time.Millisecond)
+	assert.Nil(t, breaker.TryMethod(func() error {
+		return nil
+	}))
+	breaker.Isolate()
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.circuitTransitions.WithLabelValues("db", "open")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.circuitTransitions.WithLabelValues("db", "half-open")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.circuitTransitions.WithLabelValues("db", "closed")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.circuitTransitions.WithLabelValues("db", "isolated")))
+}
+
+func TestInstrumentCircuitBreakerCountsOpenWithoutBreakDuration(t *testing.T) {
+	collector, _ := newRegisteredCollector(t)
+	breaker := collector.InstrumentCircuitBreaker("db", gotry.NewCircuitBreaker().
+		WithConsecutiveFailureThreshold(1).WithBreakDuration(0))
+	_ = breaker.TryMethod(func() error {
+		return errExpected
+	})
+	assert.Equal(t, float64(1), testutil.ToFloat64(collector.circuitTransitions.WithLabelValues("db", "open")))
+	assert.Equal(t, float64(0), testutil.ToFloat64(collector.circuitTransitions.WithLabelValues("db", "half-open")))
+}
@@ -0,0 +1,187 @@
+// Package otel traces policy executions with OpenTelemetry, one span per execution with a child span
+// per attempt.
+package otel
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lonegunmanb/gotry"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	PolicyKey    = attribute.Key("gotry.policy")
+	AttemptKey   = attribute.Key("gotry.attempt")
+	AttemptsKey  = attribute.Key("gotry.attempts")
+	OutcomeKey   = attribute.Key("gotry.outcome")
+	PanicKey     = attribute.Key("gotry.panic")
+	TimeoutKey   = attribute.Key("gotry.timeout")
+	BackoffMsKey = attribute.Key("gotry.backoff_ms")
+)
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomePanic   = "panic"
+	OutcomeTimeout = "timeout"
+)
+
+const attemptSpanName = "attempt"
+
+// TracedPolicy runs its policy inside a span named after the policy, every attempt gets a child span
+// whose context is passed to the body, so spans started by the body nest under their attempt.
+// It is an Executor, so it can be wrapped by PolicyWrap.
+type TracedPolicy struct {
+	tracer trace.Tracer
+	name   string
+	policy gotry.Policy
+}
+
+// Instrument names policy by name and traces its executions with tracer.
+func Instrument(tracer trace.Tracer, name string, policy gotry.Policy) *TracedPolicy {
+	return &TracedPolicy{tracer: tracer, name: name, policy: policy.WithName(name)}
+}
+
+func (p *TracedPolicy) TryFunc(funcBody gotry.Func) gotry.FuncReturn {
+	return p.TryFuncContext(context.Background(), func(context.Context) gotry.FuncReturn {
+		return funcBody()
+	})
+}
+
+func (p *TracedPolicy) TryMethod(methodBody gotry.Method) error {
+	return p.TryMethodContext(context.Background(), func(context.Context) error {
+		return methodBody()
+	})
+}
+
+func (p *TracedPolicy) TryFuncContext(ctx context.Context, funcBody gotry.FuncWithContext) gotry.FuncReturn {
+	ctx, execution := p.start(ctx)
+	defer execution.end()
+	return execution.instrument(p.policy).TryFuncContext(ctx, func(ctx context.Context) gotry.FuncReturn {
+		return funcBody(execution.startAttempt(ctx))
+	})
+}
+
+func (p *TracedPolicy) TryMethodContext(ctx context.Context, methodBody gotry.MethodWithContext) error {
+	ctx, execution := p.start(ctx)
+	defer execution.end()
+	return execution.instrument(p.policy).TryMethodContext(ctx, func(ctx context.Context) error {
+		return methodBody(execution.startAttempt(ctx))
+	})
+}
+
+func (p *TracedPolicy) start(ctx context.Context) (context.Context, *execution) {
+	ctx, span := p.tracer.Start(ctx, p.name, trace.WithAttributes(PolicyKey.String(p.name)))
+	return ctx, &execution{tracer: p.tracer, name: p.name, span: span, attempts: map[int]trace.Span{}}
+}
+
+// execution keeps the spans of one execution, the hooks of the policy fire on the goroutine running
+// the attempts, which may be abandoned by timeout.
+type execution struct {
+	tracer      trace.Tracer
+	name        string
+	span        trace.Span
+	mutex       sync.Mutex
+	started     int
+	nextBackoff time.Duration
+	attempts    map[int]trace.Span
+}
+
+// instrument derives a policy reporting to this execution only.
+func (e *execution) instrument(policy gotry.Policy) gotry.Policy {
+	return policy.
+		WithOnRetryEx(e.onRetry).
+		WithOnAttemptDone(e.onAttemptDone).
+		WithOnTimeoutEx(e.onTimeout).
+		WithOnExecutionDone(e.onExecutionDone)
+}
+
+func (e *execution) startAttempt(ctx context.Context) context.Context {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.started++
+	ctx, span := e.tracer.Start(ctx, attemptSpanName, trace.WithAttributes(
+		PolicyKey.String(e.name),
+		AttemptKey.Int(e.started),
+		BackoffMsKey.Int64(e.nextBackoff.Milliseconds()),
+	))
+	e.attempts[e.started] = span
+	return ctx
+}
+
+func (e *execution) onRetry(info gotry.AttemptInfo) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.nextBackoff = info.NextDelay
+}
+
+func (e *execution) onAttemptDone(info gotry.AttemptInfo) {
+	e.endAttempt(info)
+}
+
+// onTimeout ends the attempt overall timeout gave up on, its body may still be running.
+func (e *execution) onTimeout(info gotry.AttemptInfo) {
+	e.span.SetAttributes(TimeoutKey.Bool(true))
+	e.endAttempt(info)
+}
+
+func (e *execution) onExecutionDone(info gotry.AttemptInfo) {
+	e.annotate(e.span, info)
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.span.SetAttributes(AttemptsKey.Int(e.started))
+}
+
+func (e *execution) endAttempt(info gotry.AttemptInfo) {
+	e.mutex.Lock()
+	span, ok := e.attempts[info.Attempt]
+	delete(e.attempts, info.Attempt)
+	e.mutex.Unlock()
+	if !ok {
+		return
+	}
+	e.annotate(span, info)
+	span.End()
+}
+
+func (e *execution) annotate(span trace.Span, info gotry.AttemptInfo) {
+	panicked := info.PanicValue != nil
+	timedOut := errors.Is(info.LastError, gotry.TimeoutError)
+	outcome := OutcomeFailure
+	switch {
+	case info.Succeeded:
+		outcome = OutcomeSuccess
+	case panicked:
+		outcome = OutcomePanic
+	case timedOut:
+		outcome = OutcomeTimeout
+	}
+	span.SetAttributes(OutcomeKey.String(outcome), PanicKey.Bool(panicked), TimeoutKey.Bool(timedOut))
+	if info.Succeeded {
+		span.SetStatus(codes.Ok, "")
+		return
+	}
+	if info.LastError != nil {
+		span.RecordError(info.LastError)
+		span.SetStatus(codes.Error, info.LastError.Error())
+	} else {
+		span.SetStatus(codes.Error, "invalid return value")
+	}
+}
+
+// end ends the attempts nobody reported, e.g. when the policy panicked, then the execution.
+func (e *execution) end() {
+	e.mutex.Lock()
+	attempts := e.attempts
+	e.attempts = map[int]trace.Span{}
+	e.mutex.Unlock()
+	for _, span := range attempts {
+		span.End()
+	}
+	e.span.End()
+}
@@ -0,0 +1,133 @@
+package otel
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lonegunmanb/gotry"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var errExpected = errors.New("expected")
+
+func newTracer() (trace.Tracer, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	return provider.Tracer("gotry"), exporter
+}
+
+func attributesOf(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	attributes := map[attribute.Key]attribute.Value{}
+	for _, kv := range span.Attributes {
+		attributes[kv.Key] = kv.Value
+	}
+	return attributes
+}
+
+func spansNamed(spans tracetest.SpanStubs, name string) []tracetest.SpanStub {
+	var named []tracetest.SpanStub
+	for _, span := range spans {
+		if span.Name == name {
+			named = append(named, span)
+		}
+	}
+	return named
+}
+
+func TestAttemptSpansAreChildrenOfExecutionSpan(t *testing.T) {
+	tracer, exporter := newTracer()
+	policy := Instrument(tracer, "payment", gotry.NewPolicy().WithRetryLimit(1).
+		WithBackoff(gotry.NewConstantBackoff(time.Millisecond)))
+	invoked := 0
+	err := policy.TryMethodContext(context.Background(), func(ctx context.Context) error {
+		invoked++
+		_, span := tracer.Start(ctx, "query")
+		span.End()
+		if invoked == 1 {
+			return errExpected
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	spans := exporter.GetSpans()
+	executions := spansNamed(spans, "payment")
+	attempts := spansNamed(spans, attemptSpanName)
+	queries := spansNamed(spans, "query")
+	assert.Equal(t, 1, len(executions))
+	assert.Equal(t, 2, len(attempts))
+	assert.Equal(t, 2, len(queries))
+	execution := executions[0]
+	assert.Equal(t, codes.Ok, execution.Status.Code)
+	assert.Equal(t, int64(2), attributesOf(execution)[AttemptsKey].AsInt64())
+	assert.Equal(t, OutcomeSuccess, attributesOf(execution)[OutcomeKey].AsString())
+	for i, attempt := range attempts {
+		assert.Equal(t, execution.SpanContext.SpanID(), attempt.Parent.SpanID())
+		assert.Equal(t, attempt.SpanContext.SpanID(), queries[i].Parent.SpanID())
+		assert.Equal(t, int64(i+1), attributesOf(attempt)[AttemptKey].AsInt64())
+		assert.Equal(t, "payment", attributesOf(attempt)[PolicyKey].AsString())
+	}
+	assert.Equal(t, OutcomeFailure, attributesOf(attempts[0])[OutcomeKey].AsString())
+	assert.Equal(t, codes.Error, attempts[0].Status.Code)
+	assert.Equal(t, int64(0), attributesOf(attempts[0])[BackoffMsKey].AsInt64())
+	assert.Equal(t, OutcomeSuccess, attributesOf(attempts[1])[OutcomeKey].AsString())
+	assert.Equal(t, int64(1), attributesOf(attempts[1])[BackoffMsKey].AsInt64())
+}
+
+func TestPanickedAttemptIsFlagged(t *testing.T) {
+	tracer, exporter := newTracer()
+	policy := Instrument(tracer, "panic", gotry.NewPolicy().WithRetryLimit(1))
+	invoked := 0
+	funcReturn := policy.TryFunc(func() gotry.FuncReturn {
+		invoked++
+		if invoked == 1 {
+			panic("boom")
+		}
+		return gotry.FuncReturn{ReturnValue: 1, Valid: true}
+	})
+	assert.Nil(t, funcReturn.Err)
+	attempts := spansNamed(exporter.GetSpans(), attemptSpanName)
+	assert.Equal(t, 2, len(attempts))
+	assert.True(t, attributesOf(attempts[0])[PanicKey].AsBool())
+	assert.Equal(t, OutcomePanic, attributesOf(attempts[0])[OutcomeKey].AsString())
+	assert.False(t, attributesOf(attempts[1])[PanicKey].AsBool())
+}
+
+func TestEscapingPanicEndsSpans(t *testing.T) {
+	tracer, exporter := newTracer()
+	policy := Instrument(tracer, "panic", gotry.NewPolicy())
+	assert.Panics(t, func() {
+		policy.TryMethod(func() error {
+			panic("boom")
+		})
+	})
+	spans := exporter.GetSpans()
+	assert.Equal(t, 1, len(spansNamed(spans, attemptSpanName)))
+	execution := spansNamed(spans, "panic")[0]
+	assert.Equal(t, OutcomePanic, attributesOf(execution)[OutcomeKey].AsString())
+	assert.Equal(t, codes.Error, execution.Status.Code)
+}
+
+func TestTimedOutAttemptIsFlagged(t *testing.T) {
+	tracer, exporter := newTracer()
+	policy := Instrument(tracer, "slow", gotry.NewPolicy().WithOverallTimeout(time.Millisecond))
+	err := policy.TryMethodContext(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Equal(t, gotry.TimeoutError, err)
+	spans := exporter.GetSpans()
+	attempts := spansNamed(spans, attemptSpanName)
+	assert.Equal(t, 1, len(attempts))
+	assert.True(t, attributesOf(attempts[0])[TimeoutKey].AsBool())
+	assert.Equal(t, OutcomeTimeout, attributesOf(attempts[0])[OutcomeKey].AsString())
+	execution := spansNamed(spans, "slow")[0]
+	assert.True(t, attributesOf(execution)[TimeoutKey].AsBool())
+	assert.Equal(t, OutcomeTimeout, attributesOf(execution)[OutcomeKey].AsString())
+}
@@ -0,0 +1,30 @@
+package gotry
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// PanicError is a recovered panic, with the stack of the goroutine that recovered it.
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, e.g. a runtime.Error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
+
+// newPanicError must be called by the deferred function recovering value, so the stack still shows
+// where the panic was raised.
+func newPanicError(value interface{}) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
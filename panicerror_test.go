@@ -0,0 +1,73 @@
+package gotry
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPanicOfFinalAttemptIsReturnedAsPanicError(t *testing.T) {
+	cancellation := &cancellation{}
+	policy := NewPolicy().WithRetryForever().WithBackoff(NewConstantBackoff(time.Second)).
+		WithOnPanic(func(interface{}) {
+			go cancellation.Cancel()
+		})
+	funcReturn := policy.TryFuncWithCancellation(panicFunc, cancellation)
+	var panicError *PanicError
+	assert.True(t, errors.As(funcReturn.Err, &panicError))
+	assert.False(t, funcReturn.Valid)
+	assert.Equal(t, PanicContent, panicError.Value)
+	assert.Equal(t, "panic: "+PanicContent, panicError.Error())
+}
+
+func TestPanicErrorWrappedByRetryBudgetExhaustedError(t *testing.T) {
+	budget := NewRetryBudget(0, 0, time.Minute)
+	err := NewPolicy().WithRetryLimit(1).WithRetryBudget(budget).TryMethod(panicMethod)
+	var panicError *PanicError
+	assert.True(t, errors.As(err, &panicError))
+	assert.True(t, errors.Is(err, RetryBudgetExhaustedError))
+}
+
+func TestOnPanicReceivesPanicErrorWithStack(t *testing.T) {
+	var panicErrors []interface{}
+	policy := NewPolicy().WithLetItPanic().WithOnPanic(func(panicError interface{}) {
+		panicErrors = append(panicErrors, panicError)
+	})
+	assert.PanicsWithValue(t, PanicContent, func() {
+		policy.TryMethod(panicMethod)
+	})
+	assert.Equal(t, 1, len(panicErrors))
+	panicError, ok := panicErrors[0].(*PanicError)
+	assert.True(t, ok)
+	assert.Equal(t, PanicContent, panicError.Value)
+	assert.True(t, strings.Contains(string(panicError.Stack), "retrytestbase.go"))
+}
+
+func TestPanicErrorUnderAttemptTimeoutKeepsBodyStack(t *testing.T) {
+	var panicErrors []interface{}
+	policy := NewPolicy().WithAttemptTimeout(time.Minute).WithLetItPanic().WithOnPanic(func(panicError interface{}) {
+		panicErrors = append(panicErrors, panicError)
+	})
+	assert.PanicsWithValue(t, PanicContent, func() {
+		policy.TryMethod(panicMethod)
+	})
+	assert.Equal(t, 1, len(panicErrors))
+	panicError, ok := panicErrors[0].(*PanicError)
+	assert.True(t, ok)
+	assert.Equal(t, PanicContent, panicError.Value)
+	assert.True(t, strings.Contains(string(panicError.Stack), "retrytestbase.go"))
+}
+
+func TestPanicErrorUnwrapsErrorValue(t *testing.T) {
+	err := NewPolicy().WithMethodFallback(func(err error) error {
+		return err
+	}).TryMethod(func() error {
+		panic(ExpectedError)
+	})
+	var panicError *PanicError
+	assert.True(t, errors.As(err, &panicError))
+	assert.True(t, errors.Is(err, ExpectedError))
+}
@@ -23,6 +23,7 @@ func (suite *policyEventTestSuite) SetupTest(){
 	suite.mock.On(OnMethodErrorMethodName, 0, ExpectedError).Return()
 	suite.mock.On(OnPanicMethodName, ExpectedError).Return()
 	suite.mock.On(OnTimeoutMethodName, time.Second).Return()
+	suite.mock.On(OnAttemptTimeoutMethodName, 0, time.Second).Return()
 }
 
 func (suite *policyEventTestSuite) TestSingleOnFuncRetryEvent(){
@@ -81,6 +82,17 @@ func (suite *policyEventTestSuite) TestMultipleOnTimeoutEvents(){
 	suite.mock.AssertNumberOfCalls(suite.T(), OnTimeoutMethodName, 2)
 }
 
+func (suite *policyEventTestSuite) TestMultipleOnAttemptTimeoutEvents(){
+	for i := 0; i < 2; i++ {
+		suite.p = suite.p.WithOnAttemptTimeout(func(retriedCount int, timeout time.Duration) {
+			suite.mock.OnAttemptTimeout(retriedCount, timeout)
+		}).(*policy)
+	}
+	suite.p.onAttemptTimeout(0, time.Second)
+	suite.mock.AssertCalled(suite.T(), OnAttemptTimeoutMethodName, 0, time.Second)
+	suite.mock.AssertNumberOfCalls(suite.T(), OnAttemptTimeoutMethodName, 2)
+}
+
 func (suite *policyEventTestSuite) TestMultipleOnMethodRetryEvents(){
 	for i := 0; i < 2; i++ {
 		suite.p = suite.p.WithOnMethodRetry(func(retriedCount int, err error) {
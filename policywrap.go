@@ -0,0 +1,80 @@
+package gotry
+
+import "context"
+
+// Executor is anything able to run a Func or a Method under some resilience strategy,
+// e.g. Policy, CircuitBreaker or PolicyWrap. The context passed to the body of TryFuncContext and
+// TryMethodContext must be done once the executor gives up on it, e.g. by timeout, so inner layers
+// of a PolicyWrap stop with it.
+type Executor interface {
+	TryFunc(funcBody Func) FuncReturn
+	TryMethod(methodBody Method) error
+	TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn
+	TryMethodContext(ctx context.Context, methodBody MethodWithContext) error
+}
+
+// PolicyWrap runs body through a stack of executors, from the outermost to the innermost.
+type PolicyWrap interface {
+	Executor
+	Wrap(inner Executor) PolicyWrap
+	Executors() []Executor
+}
+
+type policyWrap struct {
+	executors []Executor
+}
+
+// NewPolicyWrap stacks executors, the first one is the outermost. A typical order is
+// fallback, overall timeout, retry, circuit breaker, per-attempt timeout, bulkhead.
+// Each layer fires its own events, outer layers see the outcome of inner layers as the outcome of body.
+func NewPolicyWrap(executors ...Executor) PolicyWrap {
+	return &policyWrap{executors: append([]Executor(nil), executors...)}
+}
+
+// Wrap returns a new PolicyWrap with inner as the innermost layer.
+func (w *policyWrap) Wrap(inner Executor) PolicyWrap {
+	return NewPolicyWrap(append(w.Executors(), inner)...)
+}
+
+func (w *policyWrap) Executors() []Executor {
+	return append([]Executor(nil), w.executors...)
+}
+
+func (w *policyWrap) TryFunc(funcBody Func) FuncReturn {
+	return w.TryFuncContext(context.Background(), funcBody.ignoreContext())
+}
+
+func (w *policyWrap) TryMethod(methodBody Method) error {
+	return w.TryMethodContext(context.Background(), func(context.Context) error {
+		return methodBody()
+	})
+}
+
+// TryFuncContext hands every layer the context of the layer around it, so once an outer layer gives
+// up, e.g. by timeout, the inner ones stop retrying too.
+func (w *policyWrap) TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn {
+	return tryFuncThrough(ctx, w.executors, funcBody)
+}
+
+func (w *policyWrap) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	return tryMethodThrough(ctx, w.executors, methodBody)
+}
+
+func tryFuncThrough(ctx context.Context, executors []Executor, funcBody FuncWithContext) FuncReturn {
+	if len(executors) == 0 {
+		return funcBody(ctx)
+	}
+	return executors[0].TryFuncContext(ctx, func(ctx context.Context) FuncReturn {
+		return tryFuncThrough(ctx, executors[1:], funcBody)
+	})
+}
+
+// tryMethodThrough keeps the body a Method all the way down, so method events of every layer fire.
+func tryMethodThrough(ctx context.Context, executors []Executor, methodBody MethodWithContext) error {
+	if len(executors) == 0 {
+		return methodBody(ctx)
+	}
+	return executors[0].TryMethodContext(ctx, func(ctx context.Context) error {
+		return tryMethodThrough(ctx, executors[1:], methodBody)
+	})
+}
@@ -0,0 +1,135 @@
+package gotry
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+type recordingExecutor struct {
+	name  string
+	trace *[]string
+}
+
+func (e *recordingExecutor) TryFunc(funcBody Func) FuncReturn {
+	*e.trace = append(*e.trace, e.name+" enter")
+	defer func() {
+		*e.trace = append(*e.trace, e.name+" exit")
+	}()
+	return funcBody()
+}
+
+func (e *recordingExecutor) TryMethod(methodBody Method) error {
+	return e.TryFunc(methodBody.convertToFunc()).Err
+}
+
+func (e *recordingExecutor) TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn {
+	return e.TryFunc(func() FuncReturn {
+		return funcBody(ctx)
+	})
+}
+
+func (e *recordingExecutor) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	return e.TryFuncContext(ctx, methodBody.convertToFunc()).Err
+}
+
+func TestPolicyWrapRunsOuterToInner(t *testing.T) {
+	var trace []string
+	wrap := NewPolicyWrap(&recordingExecutor{"outer", &trace}, &recordingExecutor{"middle", &trace}).
+		Wrap(&recordingExecutor{"inner", &trace})
+	funcReturn := wrap.TryFunc(func() FuncReturn {
+		trace = append(trace, "body")
+		return successFunc()
+	})
+	assert.Equal(t, ExpectedReturnValue, funcReturn.ReturnValue)
+	assert.Equal(t, []string{"outer enter", "middle enter", "inner enter", "body",
+		"inner exit", "middle exit", "outer exit"}, trace)
+	assert.Len(t, wrap.Executors(), 3)
+}
+
+func TestEmptyPolicyWrapRunsBody(t *testing.T) {
+	assert.Equal(t, ExpectedError, NewPolicyWrap().TryMethod(errorMethod))
+}
+
+func TestPolicyWrapFiresEventsOfEachLayer(t *testing.T) {
+	var outerRetried, innerRetried []int
+	outer := NewPolicy().WithRetryLimit(1).WithOnMethodRetry(func(retriedCount int, err error) {
+		outerRetried = append(outerRetried, retriedCount)
+	})
+	inner := NewPolicy().WithRetryLimit(2).WithOnMethodRetry(func(retriedCount int, err error) {
+		innerRetried = append(innerRetried, retriedCount)
+	})
+	invoked := 0
+	err := NewPolicyWrap(outer, inner).TryMethod(func() error {
+		invoked++
+		return ExpectedError
+	})
+	assert.Equal(t, ExpectedError, err)
+	assert.Equal(t, 6, invoked)
+	assert.Equal(t, []int{0, 1}, outerRetried)
+	assert.Equal(t, []int{0, 1, 2, 0, 1, 2}, innerRetried)
+}
+
+func TestRetryWrapsCircuitBreaker(t *testing.T) {
+	breaker := NewCircuitBreaker().WithConsecutiveFailureThreshold(2).WithBreakDuration(time.Minute)
+	invoked := 0
+	err := NewPolicyWrap(NewPolicy().WithRetryLimit(4), breaker).TryMethod(func() error {
+		invoked++
+		return ExpectedError
+	})
+	var brokenCircuitError *BrokenCircuitError
+	assert.True(t, errors.As(err, &brokenCircuitError))
+	assert.Equal(t, 2, invoked, "circuit breaker should stop calls reaching body")
+}
+
+func TestOverallTimeoutWrapsRetry(t *testing.T) {
+	wrap := NewPolicyWrap(NewPolicy().WithTimeout(timeout),
+		NewPolicy().WithRetryForever().WithBackoff(NewConstantBackoff(time.Millisecond)))
+	var invoked int32
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- wrap.TryMethod(func() error {
+			atomic.AddInt32(&invoked, 1)
+			return ExpectedError
+		})
+	}()
+	select {
+	case err := <-errChan:
+		assert.Equal(t, TimeoutError, err)
+	case <-time.After(time.Second):
+		assert.Fail(t, "timeout")
+	}
+	// the inner retry may be finishing its last attempt
+	time.Sleep(5 * time.Millisecond)
+	invokedAfterTimeout := atomic.LoadInt32(&invoked)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, invokedAfterTimeout, atomic.LoadInt32(&invoked), "inner retry should stop once outer timeout fires")
+}
+
+func TestPolicyWrapPassesContextToBody(t *testing.T) {
+	breaker := NewCircuitBreaker()
+	wrap := NewPolicyWrap(NewPolicy().WithTimeout(timeout), breaker, NewBulkhead(1, 0))
+	ctxChan := make(chan context.Context, 1)
+	err := wrap.TryMethodContext(context.Background(), func(ctx context.Context) error {
+		ctxChan <- ctx
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	assert.Equal(t, TimeoutError, err)
+	select {
+	case ctx := <-ctxChan:
+		assert.NotNil(t, ctx.Err(), "body should be signalled by the outer timeout")
+	case <-time.After(time.Second):
+		assert.Fail(t, "body not called")
+	}
+}
+
+func TestNestedPolicyWrap(t *testing.T) {
+	var trace []string
+	inner := NewPolicyWrap(&recordingExecutor{"inner", &trace})
+	_ = NewPolicyWrap(&recordingExecutor{"outer", &trace}, inner).TryMethod(successMethod)
+	assert.Equal(t, []string{"outer enter", "inner enter", "inner exit", "outer exit"}, trace)
+}
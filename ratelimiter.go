@@ -0,0 +1,193 @@
+package gotry
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type OnRateLimitRejected func(retryAfter time.Duration)
+
+// RateLimiter lets a limited number of calls through per time unit, rejecting the others with
+// RateLimitRejectedError or, once WithBlocking is set, making them wait for a permit.
+// Rate limiters derived from one another by With... share the same permits.
+type RateLimiter interface {
+	WithBlocking() RateLimiter
+	WithOnRateLimitRejected(onRejected OnRateLimitRejected) RateLimiter
+	WithClock(clock Clock) RateLimiter
+	TryFunc(funcBody Func) FuncReturn
+	TryMethod(methodBody Method) error
+	TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn
+	TryMethodContext(ctx context.Context, methodBody MethodWithContext) error
+	TryFuncWithCancellation(funcBody Func, cancellation Cancellation) FuncReturn
+	TryMethodWithCancellation(methodBody Method, cancellation Cancellation) error
+}
+
+// RateLimitRejectedError is returned instead of calling body when no permit is available. It implements
+// RetryAfterError, so RetryAfterDuration waits exactly until the next permit.
+type RateLimitRejectedError struct {
+	retryAfter time.Duration
+}
+
+func (e *RateLimitRejectedError) Error() string {
+	return fmt.Sprintf("rate limit rejected, retry after %v", e.retryAfter)
+}
+
+func (e *RateLimitRejectedError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+// permits hands out permits, returning how long to wait for the next one when none is available.
+type permits interface {
+	tryAcquire(now time.Time) (bool, time.Duration)
+}
+
+type rateLimiter struct {
+	blocking   bool
+	onRejected OnRateLimitRejected
+	clock      Clock
+	permits    permits
+}
+
+// NewTokenBucketRateLimiter refills permitsPerSecond permits every second, and lets up to burst
+// permits accumulate while idle.
+func NewTokenBucketRateLimiter(permitsPerSecond float64, burst int) RateLimiter {
+	if permitsPerSecond <= 0 || burst <= 0 {
+		panic("gotry: token bucket needs positive rate and burst")
+	}
+	return &rateLimiter{clock: SystemClock, permits: &tokenBucket{rate: permitsPerSecond, burst: float64(burst), tokens: float64(burst)}}
+}
+
+// NewSlidingWindowRateLimiter lets at most limit calls through within any window.
+func NewSlidingWindowRateLimiter(limit int, window time.Duration) RateLimiter {
+	if limit <= 0 || window <= 0 {
+		panic("gotry: sliding window needs positive limit and window")
+	}
+	return &rateLimiter{clock: SystemClock, permits: &slidingWindow{limit: limit, window: window}}
+}
+
+// WithBlocking waits for a permit instead of rejecting, the wait ends early on cancellation.
+func (l rateLimiter) WithBlocking() RateLimiter {
+	l.blocking = true
+	return &l
+}
+
+func (l rateLimiter) WithOnRateLimitRejected(onRejected OnRateLimitRejected) RateLimiter {
+	originEvent := l.onRejected
+	if originEvent != nil {
+		l.onRejected = func(retryAfter time.Duration) {
+			originEvent(retryAfter)
+			onRejected(retryAfter)
+		}
+	} else {
+		l.onRejected = onRejected
+	}
+	return &l
+}
+
+func (l rateLimiter) WithClock(clock Clock) RateLimiter {
+	l.clock = clockOrSystem(clock)
+	return &l
+}
+
+func (l *rateLimiter) TryFunc(funcBody Func) FuncReturn {
+	return l.TryFuncWithCancellation(funcBody, nil)
+}
+
+func (l *rateLimiter) TryMethod(methodBody Method) error {
+	return l.TryFunc(methodBody.convertToFunc()).Err
+}
+
+func (l *rateLimiter) TryMethodWithCancellation(methodBody Method, cancellation Cancellation) error {
+	return l.TryFuncWithCancellation(methodBody.convertToFunc(), cancellation).Err
+}
+
+func (l *rateLimiter) TryFuncWithCancellation(funcBody Func, cancellation Cancellation) FuncReturn {
+	if err := l.acquire(cancellation); err != nil {
+		return FuncReturn{Valid: false, Err: err}
+	}
+	return funcBody()
+}
+
+// TryFuncContext stops waiting for a permit once ctx is done.
+func (l *rateLimiter) TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn {
+	ctxCancellation := newContextCancellation(ctx)
+	// unlinks ctxCancellation from ctx once we are done
+	defer ctxCancellation.Cancel()
+	if err := l.acquire(ctxCancellation); err != nil {
+		return FuncReturn{Valid: false, Err: err}
+	}
+	return funcBody(ctx)
+}
+
+func (l *rateLimiter) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	return l.TryFuncContext(ctx, methodBody.convertToFunc()).Err
+}
+
+func (l *rateLimiter) acquire(cancellation Cancellation) error {
+	for {
+		acquired, retryAfter := l.permits.tryAcquire(l.clock.Now())
+		if acquired {
+			return nil
+		}
+		if !l.blocking {
+			if l.onRejected != nil {
+				l.onRejected(retryAfter)
+			}
+			return &RateLimitRejectedError{retryAfter: retryAfter}
+		}
+		if !waitOrCancel(l.clock, retryAfter, cancellation) {
+			return &RateLimitRejectedError{retryAfter: retryAfter}
+		}
+	}
+}
+
+type tokenBucket struct {
+	mutex    sync.Mutex
+	rate     float64
+	burst    float64
+	tokens   float64
+	lastFill time.Time
+}
+
+func (b *tokenBucket) tryAcquire(now time.Time) (bool, time.Duration) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if !b.lastFill.IsZero() && now.After(b.lastFill) {
+		b.tokens += now.Sub(b.lastFill).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	if now.After(b.lastFill) {
+		b.lastFill = now
+	}
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+type slidingWindow struct {
+	mutex   sync.Mutex
+	limit   int
+	window  time.Duration
+	granted []time.Time
+}
+
+func (w *slidingWindow) tryAcquire(now time.Time) (bool, time.Duration) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	expired := 0
+	for expired < len(w.granted) && now.Sub(w.granted[expired]) >= w.window {
+		expired++
+	}
+	w.granted = w.granted[expired:]
+	if len(w.granted) < w.limit {
+		w.granted = append(w.granted, now)
+		return true, 0
+	}
+	return false, w.granted[0].Add(w.window).Sub(now)
+}
@@ -0,0 +1,106 @@
+package gotry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenBucketAllowsBurst(t *testing.T) {
+	var rejected []time.Duration
+	limiter := NewTokenBucketRateLimiter(1, 2).WithOnRateLimitRejected(func(retryAfter time.Duration) {
+		rejected = append(rejected, retryAfter)
+	})
+	assert.Nil(t, limiter.TryMethod(successMethod))
+	assert.Nil(t, limiter.TryMethod(successMethod))
+	err := limiter.TryMethod(successMethod)
+	var rejectedError *RateLimitRejectedError
+	assert.True(t, errors.As(err, &rejectedError))
+	assert.True(t, rejectedError.RetryAfter() > 0 && rejectedError.RetryAfter() <= time.Second)
+	assert.Equal(t, []time.Duration{rejectedError.RetryAfter()}, rejected)
+}
+
+func TestTokenBucketRefills(t *testing.T) {
+	bucket := &tokenBucket{rate: 10, burst: 1, tokens: 1}
+	now := time.Now()
+	acquired, _ := bucket.tryAcquire(now)
+	assert.True(t, acquired)
+	acquired, retryAfter := bucket.tryAcquire(now)
+	assert.False(t, acquired)
+	assert.Equal(t, 100*time.Millisecond, retryAfter)
+	acquired, _ = bucket.tryAcquire(now.Add(retryAfter))
+	assert.True(t, acquired)
+	acquired, _ = bucket.tryAcquire(now.Add(time.Hour).Add(retryAfter))
+	assert.True(t, acquired)
+	acquired, _ = bucket.tryAcquire(now.Add(time.Hour).Add(retryAfter))
+	assert.False(t, acquired, "tokens should not accumulate beyond burst")
+}
+
+func TestSlidingWindow(t *testing.T) {
+	window := &slidingWindow{limit: 2, window: time.Second}
+	now := time.Now()
+	acquired, _ := window.tryAcquire(now)
+	assert.True(t, acquired)
+	acquired, _ = window.tryAcquire(now.Add(500 * time.Millisecond))
+	assert.True(t, acquired)
+	acquired, retryAfter := window.tryAcquire(now.Add(600 * time.Millisecond))
+	assert.False(t, acquired)
+	assert.Equal(t, 400*time.Millisecond, retryAfter)
+	acquired, _ = window.tryAcquire(now.Add(time.Second))
+	assert.True(t, acquired)
+}
+
+func TestBlockingRateLimiterWaitsForPermit(t *testing.T) {
+	limiter := NewSlidingWindowRateLimiter(1, timeout).WithBlocking()
+	start := time.Now()
+	assert.Nil(t, limiter.TryMethod(successMethod))
+	assert.Nil(t, limiter.TryMethod(successMethod))
+	assert.True(t, time.Since(start) >= timeout)
+}
+
+func TestBlockingRateLimiterRespectsCancellation(t *testing.T) {
+	limiter := NewTokenBucketRateLimiter(0.001, 1).WithBlocking()
+	assert.Nil(t, limiter.TryMethod(successMethod))
+	cancellation := NewCancellation()
+	time.AfterFunc(timeout, func() {
+		cancellation.Cancel()
+	})
+	invoked := false
+	err := limiter.TryMethodWithCancellation(func() error {
+		invoked = true
+		return nil
+	}, cancellation)
+	var rejectedError *RateLimitRejectedError
+	assert.True(t, errors.As(err, &rejectedError))
+	assert.False(t, invoked)
+}
+
+func TestBlockingRateLimiterRespectsCustomCancellation(t *testing.T) {
+	limiter := NewTokenBucketRateLimiter(0.001, 1).WithBlocking()
+	assert.Nil(t, limiter.TryMethod(successMethod))
+	cancellation := &customCancellation{}
+	time.AfterFunc(timeout, func() {
+		cancellation.Cancel()
+	})
+	start := time.Now()
+	err := limiter.TryMethodWithCancellation(successMethod, cancellation)
+	var rejectedError *RateLimitRejectedError
+	assert.True(t, errors.As(err, &rejectedError))
+	assert.True(t, time.Since(start) < time.Second)
+}
+
+func TestRetryHonoursRateLimitRejection(t *testing.T) {
+	limiter := NewSlidingWindowRateLimiter(1, timeout)
+	assert.Nil(t, limiter.TryMethod(successMethod))
+	var retryAfter time.Duration
+	policy := NewPolicy().WithRetryLimit(1).WithSleepDurationProvider(RetryAfterDuration).
+		WithOnMethodRetry(func(retriedCount int, err error) {
+			retryAfter = err.(*RateLimitRejectedError).RetryAfter()
+		})
+	start := time.Now()
+	assert.Nil(t, NewPolicyWrap(policy, limiter).TryMethod(successMethod))
+	assert.True(t, retryAfter > 0)
+	assert.True(t, time.Since(start) >= retryAfter)
+}
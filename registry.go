@@ -0,0 +1,139 @@
+package gotry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var PolicyAlreadyRegisteredError = errors.New("policy already registered")
+var PolicyNotRegisteredError = errors.New("policy not registered")
+
+// Registry shares policies by name across packages, it is safe for concurrent use.
+type Registry struct {
+	mutex    sync.RWMutex
+	policies map[string]Policy
+}
+
+func NewRegistry() *Registry {
+	return &Registry{policies: map[string]Policy{}}
+}
+
+// Add registers policy under name, it returns PolicyAlreadyRegisteredError if name is taken.
+func (r *Registry) Add(name string, policy Policy) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.policies[name]; ok {
+		return fmt.Errorf("%w: %q", PolicyAlreadyRegisteredError, name)
+	}
+	r.policies[name] = policy
+	return nil
+}
+
+// Replace swaps the policy registered under name, callers using a Handle of name pick it up at their
+// next call. It returns PolicyNotRegisteredError if name is not registered.
+func (r *Registry) Replace(name string, policy Policy) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if _, ok := r.policies[name]; !ok {
+		return fmt.Errorf("%w: %q", PolicyNotRegisteredError, name)
+	}
+	r.policies[name] = policy
+	return nil
+}
+
+func (r *Registry) Remove(name string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	delete(r.policies, name)
+}
+
+// Get returns the policy registered under name right now, use Handle to follow replacements.
+func (r *Registry) Get(name string) (Policy, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	policy, ok := r.policies[name]
+	return policy, ok
+}
+
+// Names lists the registered names in order.
+func (r *Registry) Names() []string {
+	r.mutex.RLock()
+	names := make([]string, 0, len(r.policies))
+	for name := range r.policies {
+		names = append(names, name)
+	}
+	r.mutex.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// Handle returns an Executor running every call by the policy registered under name at the time of
+// the call. Calls fail with PolicyNotRegisteredError while name is not registered.
+func (r *Registry) Handle(name string) *PolicyHandle {
+	return &PolicyHandle{registry: r, name: name}
+}
+
+// PolicyHandle is an Executor, so it can be wrapped by PolicyWrap.
+type PolicyHandle struct {
+	registry *Registry
+	name     string
+}
+
+func (h *PolicyHandle) TryFunc(funcBody Func) FuncReturn {
+	policy, err := h.policy()
+	if err != nil {
+		return FuncReturn{Valid: false, Err: err}
+	}
+	return policy.TryFunc(funcBody)
+}
+
+func (h *PolicyHandle) TryMethod(methodBody Method) error {
+	policy, err := h.policy()
+	if err != nil {
+		return err
+	}
+	return policy.TryMethod(methodBody)
+}
+
+func (h *PolicyHandle) TryFuncWithCancellation(funcBody Func, cancellation Cancellation) FuncReturn {
+	policy, err := h.policy()
+	if err != nil {
+		return FuncReturn{Valid: false, Err: err}
+	}
+	return policy.TryFuncWithCancellation(funcBody, cancellation)
+}
+
+func (h *PolicyHandle) TryMethodWithCancellation(methodBody Method, cancellation Cancellation) error {
+	policy, err := h.policy()
+	if err != nil {
+		return err
+	}
+	return policy.TryMethodWithCancellation(methodBody, cancellation)
+}
+
+func (h *PolicyHandle) TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn {
+	policy, err := h.policy()
+	if err != nil {
+		return FuncReturn{Valid: false, Err: err}
+	}
+	return policy.TryFuncContext(ctx, funcBody)
+}
+
+func (h *PolicyHandle) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	policy, err := h.policy()
+	if err != nil {
+		return err
+	}
+	return policy.TryMethodContext(ctx, methodBody)
+}
+
+func (h *PolicyHandle) policy() (Policy, error) {
+	policy, ok := h.registry.Get(h.name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", PolicyNotRegisteredError, h.name)
+	}
+	return policy, nil
+}
@@ -0,0 +1,142 @@
+package gotry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistryAddAndGet(t *testing.T) {
+	registry := NewRegistry()
+	policy := NewPolicy().WithRetryLimit(1)
+	assert.NoError(t, registry.Add("db-write", policy))
+
+	registered, ok := registry.Get("db-write")
+	assert.True(t, ok)
+	assert.Same(t, policy, registered)
+	_, ok = registry.Get("db-read")
+	assert.False(t, ok)
+}
+
+func TestRegistryAddRejectsTakenName(t *testing.T) {
+	registry := NewRegistry()
+	policy := NewPolicy()
+	assert.NoError(t, registry.Add("db-write", policy))
+
+	err := registry.Add("db-write", NewPolicy().WithRetryLimit(1))
+	assert.True(t, errors.Is(err, PolicyAlreadyRegisteredError))
+	registered, _ := registry.Get("db-write")
+	assert.Same(t, policy, registered)
+}
+
+func TestRegistryReplace(t *testing.T) {
+	registry := NewRegistry()
+	err := registry.Replace("db-write", NewPolicy())
+	assert.True(t, errors.Is(err, PolicyNotRegisteredError))
+
+	assert.NoError(t, registry.Add("db-write", NewPolicy()))
+	replacement := NewPolicy().WithRetryLimit(2)
+	assert.NoError(t, registry.Replace("db-write", replacement))
+	registered, _ := registry.Get("db-write")
+	assert.Same(t, replacement, registered)
+}
+
+func TestRegistryNames(t *testing.T) {
+	registry := NewRegistry()
+	assert.Empty(t, registry.Names())
+	for _, name := range []string{"db-write", "api", "db-read"} {
+		assert.NoError(t, registry.Add(name, NewPolicy()))
+	}
+	assert.Equal(t, []string{"api", "db-read", "db-write"}, registry.Names())
+	registry.Remove("db-read")
+	assert.Equal(t, []string{"api", "db-write"}, registry.Names())
+}
+
+func TestRegistryHandleFollowsReplacement(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Add("db-write", NewPolicy()))
+	handle := registry.Handle("db-write")
+	attempts := 0
+	failing := func() error {
+		attempts++
+		return errors.New("expected error")
+	}
+
+	assert.NotNil(t, handle.TryMethod(failing))
+	assert.Equal(t, 1, attempts)
+
+	assert.NoError(t, registry.Replace("db-write", NewPolicy().WithRetryLimit(2)))
+	attempts = 0
+	assert.NotNil(t, handle.TryMethod(failing))
+	assert.Equal(t, 3, attempts)
+}
+
+func TestRegistryHandleOfUnregisteredName(t *testing.T) {
+	registry := NewRegistry()
+	handle := registry.Handle("db-write")
+	called := false
+	body := func() FuncReturn {
+		called = true
+		return successFunc()
+	}
+
+	funcReturn := handle.TryFunc(body)
+	assert.False(t, funcReturn.Valid)
+	assert.True(t, errors.Is(funcReturn.Err, PolicyNotRegisteredError))
+	err := handle.TryMethodContext(context.Background(), func(context.Context) error {
+		called = true
+		return nil
+	})
+	assert.True(t, errors.Is(err, PolicyNotRegisteredError))
+	assert.False(t, called)
+
+	assert.NoError(t, registry.Add("db-write", NewPolicy()))
+	assert.Equal(t, ExpectedReturnValue, handle.TryFunc(body).ReturnValue)
+	registry.Remove("db-write")
+	assert.True(t, errors.Is(handle.TryFunc(body).Err, PolicyNotRegisteredError))
+}
+
+func TestRegistryHandleInPolicyWrap(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Add("inner", NewPolicy().WithRetryLimit(1)))
+	wrap := NewPolicyWrap(NewPolicy(), registry.Handle("inner"))
+	attempts := 0
+	err := wrap.TryMethod(func() error {
+		attempts++
+		return errors.New("expected error")
+	})
+	assert.NotNil(t, err)
+	assert.Equal(t, 2, attempts)
+}
+
+func TestRegistryConcurrentUse(t *testing.T) {
+	registry := NewRegistry()
+	assert.NoError(t, registry.Add("db-write", NewPolicy()))
+	handle := registry.Handle("db-write")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				_ = registry.Replace("db-write", NewPolicy().WithRetryLimit(j%3))
+				_ = registry.Add(fmt.Sprintf("policy-%d-%d", i, j), NewPolicy())
+				_ = registry.Names()
+			}
+		}(i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				assert.Nil(t, handle.TryMethod(func() error {
+					return nil
+				}))
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Len(t, registry.Names(), 801)
+}
@@ -0,0 +1,282 @@
+package gotry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type OnReload func(config PolicyConfig)
+type OnReloadError func(err error)
+
+// ConfigDecoder decodes the content of a config file into config, e.g. from YAML by a library of
+// your choice.
+type ConfigDecoder func(data []byte, config *PolicyConfig) error
+
+// ReloadablePolicy runs every call by the policy built from the latest valid PolicyConfig found in a
+// file, so retry limits and timeouts can be tuned without redeploying. An invalid config is reported
+// by OnReloadError and the previous good one stays in use. Reloadable policies derived from one
+// another by With... share the same snapshot, so configure it once and share the result across
+// goroutines.
+type ReloadablePolicy interface {
+	WithOnReload(onReload OnReload) ReloadablePolicy
+	WithOnReloadError(onReloadError OnReloadError) ReloadablePolicy
+	// WithDecorator applies decorate to every snapshot, for what a config cannot say, e.g. events,
+	// logger or metrics.
+	WithDecorator(decorate func(Policy) Policy) ReloadablePolicy
+	WithPollInterval(interval time.Duration) ReloadablePolicy
+	WithClock(clock Clock) ReloadablePolicy
+	// Current returns the policy built from the latest valid config.
+	Current() Policy
+	Config() PolicyConfig
+	// Reload reads the file right now, whether it has changed or not.
+	Reload() error
+	// Watch reloads the file whenever its modification time or size changes, until ctx is done.
+	Watch(ctx context.Context)
+	TryFunc(funcBody Func) FuncReturn
+	TryMethod(methodBody Method) error
+	TryFuncWithCancellation(funcBody Func, cancellation Cancellation) FuncReturn
+	TryMethodWithCancellation(methodBody Method, cancellation Cancellation) error
+	TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn
+	TryMethodContext(ctx context.Context, methodBody MethodWithContext) error
+}
+
+const defaultReloadPollInterval = time.Second
+
+type reloadablePolicy struct {
+	source        *configSource
+	decorate      func(Policy) Policy
+	decorated     *decorationCache
+	onReload      OnReload
+	onReloadError OnReloadError
+	pollInterval  time.Duration
+	clock         Clock
+}
+
+// configSource is the state shared by reloadable policies derived from one another.
+type configSource struct {
+	path     string
+	decode   ConfigDecoder
+	mutex    sync.Mutex
+	seen     fileVersion
+	snapshot atomic.Pointer[policySnapshot]
+}
+
+type policySnapshot struct {
+	config PolicyConfig
+	policy Policy
+}
+
+// decorationCache keeps the latest snapshot decorated, so decorators run once per snapshot instead of
+// once per call.
+type decorationCache struct {
+	mutex  sync.Mutex
+	latest atomic.Pointer[decoratedSnapshot]
+}
+
+type decoratedSnapshot struct {
+	snapshot *policySnapshot
+	policy   Policy
+}
+
+func (c *decorationCache) get(snapshot *policySnapshot, decorate func(Policy) Policy) Policy {
+	if latest := c.latest.Load(); latest != nil && latest.snapshot == snapshot {
+		return latest.policy
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if latest := c.latest.Load(); latest != nil && latest.snapshot == snapshot {
+		return latest.policy
+	}
+	decorated := &decoratedSnapshot{snapshot: snapshot, policy: decorate(snapshot.policy)}
+	c.latest.Store(decorated)
+	return decorated.policy
+}
+
+// fileVersion is zero while the file cannot be read.
+type fileVersion struct {
+	modTime time.Time
+	size    int64
+}
+
+func (v fileVersion) equal(other fileVersion) bool {
+	return v.modTime.Equal(other.modTime) && v.size == other.size
+}
+
+// NewReloadablePolicy loads the config in path by decode, DecodeJSONConfig if nil. Call Watch to
+// follow the changes of the file.
+func NewReloadablePolicy(path string, decode ConfigDecoder) (ReloadablePolicy, error) {
+	if decode == nil {
+		decode = DecodeJSONConfig
+	}
+	source := &configSource{path: path, decode: decode}
+	if _, _, err := source.load(true); err != nil {
+		return nil, err
+	}
+	return &reloadablePolicy{
+		source:       source,
+		pollInterval: defaultReloadPollInterval,
+		clock:        SystemClock,
+	}, nil
+}
+
+func (r reloadablePolicy) WithOnReload(onReload OnReload) ReloadablePolicy {
+	originEvent := r.onReload
+	if originEvent != nil {
+		r.onReload = func(config PolicyConfig) {
+			originEvent(config)
+			onReload(config)
+		}
+	} else {
+		r.onReload = onReload
+	}
+	return &r
+}
+
+func (r reloadablePolicy) WithOnReloadError(onReloadError OnReloadError) ReloadablePolicy {
+	originEvent := r.onReloadError
+	if originEvent != nil {
+		r.onReloadError = func(err error) {
+			originEvent(err)
+			onReloadError(err)
+		}
+	} else {
+		r.onReloadError = onReloadError
+	}
+	return &r
+}
+
+func (r reloadablePolicy) WithDecorator(decorate func(Policy) Policy) ReloadablePolicy {
+	origin := r.decorate
+	if origin != nil {
+		r.decorate = func(policy Policy) Policy {
+			return decorate(origin(policy))
+		}
+	} else {
+		r.decorate = decorate
+	}
+	r.decorated = &decorationCache{}
+	return &r
+}
+
+func (r reloadablePolicy) WithPollInterval(interval time.Duration) ReloadablePolicy {
+	r.pollInterval = interval
+	return &r
+}
+
+func (r reloadablePolicy) WithClock(clock Clock) ReloadablePolicy {
+	r.clock = clockOrSystem(clock)
+	return &r
+}
+
+func (r *reloadablePolicy) Current() Policy {
+	snapshot := r.source.snapshot.Load()
+	if r.decorate == nil {
+		return snapshot.policy
+	}
+	return r.decorated.get(snapshot, r.decorate)
+}
+
+func (r *reloadablePolicy) Config() PolicyConfig {
+	return r.source.snapshot.Load().config
+}
+
+func (r *reloadablePolicy) Reload() error {
+	return r.reload(true)
+}
+
+func (r *reloadablePolicy) Watch(ctx context.Context) {
+	timer := r.clock.NewTimer(r.pollInterval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C():
+		}
+		_ = r.reload(false)
+		timer.Reset(r.pollInterval)
+	}
+}
+
+func (r *reloadablePolicy) TryFunc(funcBody Func) FuncReturn {
+	return r.Current().TryFunc(funcBody)
+}
+
+func (r *reloadablePolicy) TryMethod(methodBody Method) error {
+	return r.Current().TryMethod(methodBody)
+}
+
+func (r *reloadablePolicy) TryFuncWithCancellation(funcBody Func, cancellation Cancellation) FuncReturn {
+	return r.Current().TryFuncWithCancellation(funcBody, cancellation)
+}
+
+func (r *reloadablePolicy) TryMethodWithCancellation(methodBody Method, cancellation Cancellation) error {
+	return r.Current().TryMethodWithCancellation(methodBody, cancellation)
+}
+
+func (r *reloadablePolicy) TryFuncContext(ctx context.Context, funcBody FuncWithContext) FuncReturn {
+	return r.Current().TryFuncContext(ctx, funcBody)
+}
+
+func (r *reloadablePolicy) TryMethodContext(ctx context.Context, methodBody MethodWithContext) error {
+	return r.Current().TryMethodContext(ctx, methodBody)
+}
+
+func (r *reloadablePolicy) reload(force bool) error {
+	config, reloaded, err := r.source.load(force)
+	if err != nil {
+		if r.onReloadError != nil {
+			r.onReloadError(err)
+		}
+		return err
+	}
+	if reloaded && r.onReload != nil {
+		r.onReload(config)
+	}
+	return nil
+}
+
+// load swaps the snapshot for the config in the file, unless force is false and the file has not
+// changed since the last load. A file which cannot be read is reported once, until it changes again.
+func (s *configSource) load(force bool) (config PolicyConfig, reloaded bool, err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var version fileVersion
+	info, statErr := os.Stat(s.path)
+	if statErr == nil {
+		version = fileVersion{modTime: info.ModTime(), size: info.Size()}
+	}
+	if !force && version.equal(s.seen) {
+		return PolicyConfig{}, false, nil
+	}
+	s.seen = version
+	if statErr != nil {
+		return PolicyConfig{}, false, fmt.Errorf("reload %s: %w", s.path, statErr)
+	}
+	data, err := os.ReadFile(s.path)
+	if err == nil {
+		err = s.decode(data, &config)
+	}
+	if err != nil {
+		return PolicyConfig{}, false, fmt.Errorf("reload %s: %w", s.path, err)
+	}
+	policy, err := NewPolicyFromConfig(config)
+	if err != nil {
+		return PolicyConfig{}, false, fmt.Errorf("reload %s: %w", s.path, err)
+	}
+	s.snapshot.Store(&policySnapshot{config: config, policy: policy})
+	return config, true, nil
+}
+
+// DecodeJSONConfig is a ConfigDecoder rejecting unknown fields to catch typos.
+func DecodeJSONConfig(data []byte, config *PolicyConfig) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(config)
+}
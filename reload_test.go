@@ -0,0 +1,237 @@
+package gotry
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func decodeYAMLConfig(data []byte, config *PolicyConfig) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	return decoder.Decode(config)
+}
+
+func writeConfigFile(t *testing.T, path string, content string) {
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0600))
+}
+
+func newConfigFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	writeConfigFile(t, path, content)
+	return path
+}
+
+func TestReloadablePolicyLoadsYAMLAndJSON(t *testing.T) {
+	for name, content := range map[string]string{
+		"policy.yaml": "name: payment\nretryLimit: 2\n",
+		"policy.json": `{"name": "payment", "retryLimit": 2}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var decode ConfigDecoder
+			if filepath.Ext(name) == ".yaml" {
+				decode = decodeYAMLConfig
+			}
+			reloadable, err := NewReloadablePolicy(newConfigFile(t, name, content), decode)
+			assert.Nil(t, err)
+			assert.Equal(t, "payment", reloadable.Config().Name)
+			invoked := 0
+			assert.Equal(t, ExpectedError, reloadable.TryMethod(countingMethod(&invoked, ExpectedError)))
+			assert.Equal(t, 3, invoked)
+		})
+	}
+}
+
+func TestNewReloadablePolicyRejectsInvalidFile(t *testing.T) {
+	_, err := NewReloadablePolicy(newConfigFile(t, "policy.yaml", "retryLimit: -1\n"), decodeYAMLConfig)
+	var configError *ConfigError
+	assert.True(t, errors.As(err, &configError))
+	assert.Equal(t, "retryLimit", configError.Field)
+
+	_, err = NewReloadablePolicy(newConfigFile(t, "policy.yaml", "retryLimt: 1\n"), decodeYAMLConfig)
+	assert.NotNil(t, err)
+	_, err = NewReloadablePolicy(newConfigFile(t, "policy.json", `{"retryLimt": 1}`), nil)
+	assert.NotNil(t, err)
+	_, err = NewReloadablePolicy(newConfigFile(t, "policy.yaml", "retryLimit: 1\n"), nil)
+	assert.NotNil(t, err, "JSON is decoded by default")
+	_, err = NewReloadablePolicy(filepath.Join(t.TempDir(), "policy.yaml"), decodeYAMLConfig)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+}
+
+func TestReloadablePolicySwapsSnapshotOnReload(t *testing.T) {
+	path := newConfigFile(t, "policy.yaml", "retryLimit: 1\n")
+	var reloaded []PolicyConfig
+	reloadable, err := NewReloadablePolicy(path, decodeYAMLConfig)
+	assert.Nil(t, err)
+	reloadable = reloadable.WithOnReload(func(config PolicyConfig) {
+		reloaded = append(reloaded, config)
+	})
+
+	writeConfigFile(t, path, "retryLimit: 3\n")
+	assert.Nil(t, reloadable.Reload())
+	assert.Len(t, reloaded, 1)
+	assert.Equal(t, 3, *reloaded[0].RetryLimit)
+	invoked := 0
+	assert.Equal(t, ExpectedError, reloadable.TryMethod(countingMethod(&invoked, ExpectedError)))
+	assert.Equal(t, 4, invoked)
+}
+
+func TestReloadablePolicyKeepsPreviousGoodConfig(t *testing.T) {
+	path := newConfigFile(t, "policy.json", `{"retryLimit": 1}`)
+	var reloadErrors []error
+	reloadable, err := NewReloadablePolicy(path, nil)
+	assert.Nil(t, err)
+	reloadable = reloadable.
+		WithOnReload(func(PolicyConfig) {
+			assert.Fail(t, "invalid config should not be reloaded")
+		}).
+		WithOnReloadError(func(err error) {
+			reloadErrors = append(reloadErrors, err)
+		})
+
+	for _, content := range []string{`{"retryLimit": -1}`, `{"retryLimit": `, ``} {
+		writeConfigFile(t, path, content)
+		err = reloadable.Reload()
+		assert.NotNil(t, err)
+		assert.Equal(t, err, reloadErrors[len(reloadErrors)-1])
+		assert.Equal(t, 1, *reloadable.Config().RetryLimit)
+		invoked := 0
+		assert.Equal(t, ExpectedError, reloadable.TryMethod(countingMethod(&invoked, ExpectedError)))
+		assert.Equal(t, 2, invoked)
+	}
+	assert.Len(t, reloadErrors, 3)
+}
+
+func TestReloadablePolicyDerivedShareSnapshot(t *testing.T) {
+	path := newConfigFile(t, "policy.yaml", "retryLimit: 1\n")
+	reloadable, err := NewReloadablePolicy(path, decodeYAMLConfig)
+	assert.Nil(t, err)
+	derived := reloadable.WithPollInterval(time.Minute)
+
+	writeConfigFile(t, path, "retryLimit: 2\n")
+	assert.Nil(t, derived.Reload())
+	assert.Equal(t, 2, *reloadable.Config().RetryLimit)
+}
+
+func TestReloadablePolicyDecoratorSurvivesReload(t *testing.T) {
+	path := newConfigFile(t, "policy.yaml", "retryLimit: 1\n")
+	retries := 0
+	reloadable, err := NewReloadablePolicy(path, decodeYAMLConfig)
+	assert.Nil(t, err)
+	reloadable = reloadable.
+		WithDecorator(func(policy Policy) Policy {
+			return policy.WithOnMethodRetry(func(int, error) {
+				retries++
+			})
+		}).
+		WithDecorator(func(policy Policy) Policy {
+			return policy.WithName("decorated")
+		})
+	invoked := 0
+	_ = reloadable.TryMethod(countingMethod(&invoked, ExpectedError))
+	assert.Equal(t, 2, retries)
+
+	writeConfigFile(t, path, "retryLimit: 2\n")
+	assert.Nil(t, reloadable.Reload())
+	retries = 0
+	_ = reloadable.TryMethod(countingMethod(&invoked, ExpectedError))
+	assert.Equal(t, 3, retries)
+	assert.Equal(t, "decorated", reloadable.Current().(*policy).name)
+}
+
+func TestReloadablePolicyDecoratesOncePerSnapshot(t *testing.T) {
+	path := newConfigFile(t, "policy.yaml", "retryLimit: 1\n")
+	decorated := 0
+	reloadable, err := NewReloadablePolicy(path, decodeYAMLConfig)
+	assert.Nil(t, err)
+	reloadable = reloadable.WithDecorator(func(policy Policy) Policy {
+		decorated++
+		return policy
+	})
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, reloadable.TryMethod(successMethod))
+	}
+	assert.Equal(t, 1, decorated)
+	first := reloadable.Current()
+	assert.Same(t, first, reloadable.Current())
+
+	writeConfigFile(t, path, "retryLimit: 2\n")
+	assert.Nil(t, reloadable.Reload())
+	assert.Nil(t, reloadable.TryMethod(successMethod))
+	assert.Nil(t, reloadable.TryMethod(successMethod))
+	assert.Equal(t, 2, decorated)
+	assert.NotSame(t, first, reloadable.Current())
+}
+
+func TestReloadablePolicyWatch(t *testing.T) {
+	path := newConfigFile(t, "policy.yaml", "retryLimit: 1\n")
+	var reloads, reloadErrors int32
+	reloadable, err := NewReloadablePolicy(path, decodeYAMLConfig)
+	assert.Nil(t, err)
+	reloadable = reloadable.
+		WithPollInterval(time.Millisecond).
+		WithOnReload(func(PolicyConfig) {
+			atomic.AddInt32(&reloads, 1)
+		}).
+		WithOnReloadError(func(error) {
+			atomic.AddInt32(&reloadErrors, 1)
+		})
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reloadable.Watch(ctx)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	writeConfigFile(t, path, "retryLimit: 10\n")
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&reloads) == 1
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, 10, *reloadable.Config().RetryLimit)
+
+	writeConfigFile(t, path, "retryLimit: -10\n")
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&reloadErrors) == 1
+	}, time.Second, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reloadErrors), "unchanged invalid file is reported once")
+	assert.Equal(t, int32(1), atomic.LoadInt32(&reloads))
+	assert.Equal(t, 10, *reloadable.Config().RetryLimit)
+}
+
+func TestReloadablePolicyConcurrentReload(t *testing.T) {
+	path := newConfigFile(t, "policy.yaml", "retryLimit: 1\n")
+	reloadable, err := NewReloadablePolicy(path, decodeYAMLConfig)
+	assert.Nil(t, err)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				_ = reloadable.Reload()
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				assert.Equal(t, ExpectedReturnValue, reloadable.TryFunc(successFunc).ReturnValue)
+			}
+		}()
+	}
+	wg.Wait()
+}
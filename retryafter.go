@@ -0,0 +1,63 @@
+package gotry
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SleepDurationProvider decides how long to wait before the next retry from the outcome of the
+// attempt that just failed. A non-positive duration leaves the decision to Backoff.
+type SleepDurationProvider func(retriedCount int, funcReturn FuncReturn) time.Duration
+
+// RetryAfterError is implemented by errors carrying a server dictated delay, e.g. a throttling error.
+type RetryAfterError interface {
+	error
+	RetryAfter() time.Duration
+}
+
+// WithSleepDurationProvider lets the outcome of a failed attempt pick the delay before the next retry,
+// falling back to Backoff when provider returns a non-positive duration.
+func (p policy) WithSleepDurationProvider(provider SleepDurationProvider) Policy {
+	p.sleepDurationProvider = provider
+	return &p
+}
+
+// RetryAfterDuration is a SleepDurationProvider that honours the Retry-After header of an *http.Response
+// returned as ReturnValue, or the delay of a RetryAfterError found in Err by errors.As.
+func RetryAfterDuration(retriedCount int, funcReturn FuncReturn) time.Duration {
+	return RetryAfterDurationWithClock(SystemClock)(retriedCount, funcReturn)
+}
+
+// RetryAfterDurationWithClock is RetryAfterDuration reading the time an HTTP-date Retry-After is
+// compared to from clock.
+func RetryAfterDurationWithClock(clock Clock) SleepDurationProvider {
+	clock = clockOrSystem(clock)
+	return func(_ int, funcReturn FuncReturn) time.Duration {
+		var retryAfterError RetryAfterError
+		if errors.As(funcReturn.Err, &retryAfterError) {
+			return retryAfterError.RetryAfter()
+		}
+		if response, ok := funcReturn.ReturnValue.(*http.Response); ok && response != nil {
+			return parseRetryAfter(response.Header.Get("Retry-After"), clock.Now())
+		}
+		return 0
+	}
+}
+
+// parseRetryAfter understands both forms of Retry-After: delay in seconds and HTTP-date.
+func parseRetryAfter(retryAfter string, now time.Time) time.Duration {
+	retryAfter = strings.TrimSpace(retryAfter)
+	if retryAfter == "" {
+		return 0
+	}
+	if seconds, err := strconv.ParseInt(retryAfter, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return date.Sub(now)
+	}
+	return 0
+}
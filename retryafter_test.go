@@ -0,0 +1,79 @@
+package gotry
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type throttledError struct {
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+	return "throttled"
+}
+
+func (e *throttledError) RetryAfter() time.Duration {
+	return e.retryAfter
+}
+
+func responseWithRetryAfter(retryAfter string) *http.Response {
+	response := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+	response.Header.Set("Retry-After", retryAfter)
+	return response
+}
+
+func TestRetryAfterSeconds(t *testing.T) {
+	funcReturn := FuncReturn{ReturnValue: responseWithRetryAfter("120"), Valid: false}
+	assert.Equal(t, 2*time.Minute, RetryAfterDuration(0, funcReturn))
+}
+
+func TestRetryAfterHttpDate(t *testing.T) {
+	now := time.Date(2018, 7, 1, 8, 0, 0, 0, time.UTC)
+	retryAfter := now.Add(time.Minute).Format(http.TimeFormat)
+	assert.Equal(t, time.Minute, parseRetryAfter(retryAfter, now))
+}
+
+func TestRetryAfterInvalidHeader(t *testing.T) {
+	assert.Equal(t, time.Duration(0), RetryAfterDuration(0, FuncReturn{ReturnValue: responseWithRetryAfter("soon")}))
+	assert.Equal(t, time.Duration(0), RetryAfterDuration(0, FuncReturn{ReturnValue: &http.Response{Header: http.Header{}}}))
+	assert.Equal(t, time.Duration(0), RetryAfterDuration(0, FuncReturn{ReturnValue: (*http.Response)(nil)}))
+}
+
+func TestRetryAfterError(t *testing.T) {
+	funcReturn := FuncReturn{Valid: true, Err: &throttledError{time.Second}}
+	assert.Equal(t, time.Second, RetryAfterDuration(0, funcReturn))
+}
+
+func TestSleepDurationProviderOverridesBackoff(t *testing.T) {
+	const retryAfter = 5 * time.Millisecond
+	var retried []int
+	policy := NewPolicy().WithRetryLimit(1).WithBackoff(NewConstantBackoff(time.Hour)).
+		WithSleepDurationProvider(func(retriedCount int, funcReturn FuncReturn) time.Duration {
+			retried = append(retried, retriedCount)
+			return RetryAfterDuration(retriedCount, funcReturn)
+		})
+	start := time.Now()
+	err := policy.TryMethod(func() error {
+		return &throttledError{retryAfter}
+	})
+	assert.Equal(t, &throttledError{retryAfter}, err)
+	assert.True(t, time.Since(start) >= retryAfter)
+	assert.True(t, time.Since(start) < time.Hour)
+	assert.Equal(t, []int{0}, retried)
+}
+
+func TestSleepDurationProviderFallsBackToBackoff(t *testing.T) {
+	backoffCalled := false
+	policy := NewPolicy().WithRetryLimit(1).
+		WithBackoff(BackoffFunc(func(int, time.Duration) time.Duration {
+			backoffCalled = true
+			return 0
+		})).
+		WithSleepDurationProvider(RetryAfterDuration)
+	_ = policy.TryMethod(errorMethod)
+	assert.True(t, backoffCalled)
+}
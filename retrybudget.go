@@ -0,0 +1,96 @@
+package gotry
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RetryBudget caps retries across every policy sharing it, so an outage does not multiply traffic.
+// Each execution deposits once before its first attempt, and each retry has to withdraw.
+type RetryBudget interface {
+	Deposit()
+	TryWithdraw() bool
+}
+
+// RetryBudgetExhaustedError marks the error returned when a retry was denied by RetryBudget,
+// the last error of the execution is still matched by errors.Is/As.
+var RetryBudgetExhaustedError = errors.New("retry budget exhausted")
+
+const (
+	depositCounter = iota
+	withdrawalCounter
+)
+
+type retryBudget struct {
+	mutex      sync.Mutex
+	retryRatio float64
+	reserve    float64
+	ttl        time.Duration
+	clock      Clock
+	counts     rollingCounts
+}
+
+// NewRetryBudget lets retries make up at most retryRatio of the executions within ttl, on top of
+// minRetriesPerSecond retries which are always allowed so low traffic can still retry.
+func NewRetryBudget(retryRatio float64, minRetriesPerSecond float64, ttl time.Duration) RetryBudget {
+	return NewRetryBudgetWithClock(retryRatio, minRetriesPerSecond, ttl, SystemClock)
+}
+
+// NewRetryBudgetWithClock is NewRetryBudget reading time from clock.
+func NewRetryBudgetWithClock(retryRatio float64, minRetriesPerSecond float64, ttl time.Duration, clock Clock) RetryBudget {
+	if retryRatio < 0 || minRetriesPerSecond < 0 || ttl <= 0 {
+		panic("gotry: retry budget needs non-negative ratio and minimum, and positive ttl")
+	}
+	return &retryBudget{
+		retryRatio: retryRatio,
+		reserve:    minRetriesPerSecond * ttl.Seconds(),
+		ttl:        ttl,
+		clock:      clockOrSystem(clock),
+	}
+}
+
+func (b *retryBudget) Deposit() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.counts.add(b.clock.Now(), b.ttl, depositCounter)
+}
+
+func (b *retryBudget) TryWithdraw() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	now := b.clock.Now()
+	deposits, withdrawals := b.counts.sum(now, b.ttl)
+	if b.reserve+b.retryRatio*float64(deposits)-float64(withdrawals) < 1 {
+		return false
+	}
+	b.counts.add(now, b.ttl, withdrawalCounter)
+	return true
+}
+
+// WithRetryBudget shares budget with other policies, a retry denied by budget ends the execution
+// with RetryBudgetExhaustedError wrapping the last error.
+func (p policy) WithRetryBudget(budget RetryBudget) Policy {
+	p.retryBudget = budget
+	return &p
+}
+
+func (p *policy) depositRetryBudget() {
+	if p.retryBudget != nil {
+		p.retryBudget.Deposit()
+	}
+}
+
+func (p *policy) withdrawRetryBudget() bool {
+	return p.retryBudget == nil || p.retryBudget.TryWithdraw()
+}
+
+func markRetryBudgetExhausted(lastReturn FuncReturn) FuncReturn {
+	if lastReturn.Err == nil {
+		lastReturn.Err = RetryBudgetExhaustedError
+	} else {
+		lastReturn.Err = fmt.Errorf("%w: %w", RetryBudgetExhaustedError, lastReturn.Err)
+	}
+	return lastReturn
+}
@@ -0,0 +1,73 @@
+package gotry
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryBudgetReserve(t *testing.T) {
+	budget := NewRetryBudget(0, 2, time.Second)
+	assert.True(t, budget.TryWithdraw())
+	assert.True(t, budget.TryWithdraw())
+	assert.False(t, budget.TryWithdraw())
+}
+
+func TestRetryBudgetRatio(t *testing.T) {
+	budget := NewRetryBudget(0.1, 0, time.Minute)
+	for i := 0; i < 19; i++ {
+		budget.Deposit()
+	}
+	assert.True(t, budget.TryWithdraw())
+	assert.False(t, budget.TryWithdraw())
+	budget.Deposit()
+	assert.True(t, budget.TryWithdraw())
+}
+
+func TestRetryBudgetExpires(t *testing.T) {
+	budget := NewRetryBudget(0, 0, timeout).(*retryBudget)
+	budget.reserve = 1
+	assert.True(t, budget.TryWithdraw())
+	assert.False(t, budget.TryWithdraw())
+	time.Sleep(timeout)
+	assert.True(t, budget.TryWithdraw())
+}
+
+func TestPolicyStopsRetryingWhenBudgetExhausted(t *testing.T) {
+	budget := NewRetryBudget(0, 0, time.Minute).(*retryBudget)
+	budget.reserve = 1
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(3).WithRetryBudget(budget)
+	err := policy.TryMethod(func() error {
+		invoked++
+		return ExpectedError
+	})
+	assert.Equal(t, 2, invoked)
+	assert.True(t, errors.Is(err, RetryBudgetExhaustedError))
+	assert.True(t, errors.Is(err, ExpectedError))
+}
+
+func TestPoliciesShareBudget(t *testing.T) {
+	budget := NewRetryBudget(0, 1, time.Second)
+	first := NewPolicy().WithRetryLimit(1).WithRetryBudget(budget)
+	second := NewPolicy().WithRetryLimit(1).WithRetryBudget(budget)
+	assert.Equal(t, ExpectedError, first.TryMethod(errorMethod))
+	assert.True(t, errors.Is(second.TryMethod(errorMethod), RetryBudgetExhaustedError))
+}
+
+func TestBudgetExhaustedOnInvalidReturn(t *testing.T) {
+	budget := NewRetryBudget(0, 0, time.Minute)
+	funcReturn := NewPolicy().WithRetryLimit(1).WithRetryBudget(budget).TryFunc(func() FuncReturn {
+		return FuncReturn{ExpectedReturnValue, false, nil}
+	})
+	assert.Equal(t, RetryBudgetExhaustedError, funcReturn.Err)
+	assert.Equal(t, ExpectedReturnValue, funcReturn.ReturnValue)
+}
+
+func TestSuccessDoesNotWithdraw(t *testing.T) {
+	budget := NewRetryBudget(0, 1, time.Minute)
+	assert.Nil(t, NewPolicy().WithRetryLimit(1).WithRetryBudget(budget).TryMethod(successMethod))
+	assert.True(t, budget.TryWithdraw())
+}
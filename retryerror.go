@@ -0,0 +1,46 @@
+package gotry
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RetryError is returned by a policy WithAggregatedErrors instead of the last error, it keeps every
+// attempt so errors.Is/As match the error of any attempt.
+type RetryError struct {
+	// Attempts lists the attempts in order, with their error, panic value and timing.
+	Attempts []AttemptInfo
+	// LastError is what the policy would return without WithAggregatedErrors.
+	LastError error
+}
+
+func (e *RetryError) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "failed after %d attempts: %v", len(e.Attempts), e.LastError)
+	for _, attempt := range e.Attempts {
+		fmt.Fprintf(&builder, "\n\tattempt %d after %v: ", attempt.Attempt, attempt.Elapsed)
+		if attempt.LastError != nil {
+			builder.WriteString(attempt.LastError.Error())
+		} else {
+			builder.WriteString("invalid return value")
+		}
+	}
+	return builder.String()
+}
+
+func (e *RetryError) Unwrap() []error {
+	errs := []error{e.LastError}
+	for _, attempt := range e.Attempts {
+		if attempt.LastError != nil {
+			errs = append(errs, attempt.LastError)
+		}
+	}
+	return errs
+}
+
+// WithAggregatedErrors returns *RetryError holding every attempt instead of the last error,
+// once the execution failed with an error.
+func (p policy) WithAggregatedErrors() Policy {
+	p.aggregateErrors = true
+	return &p
+}
@@ -0,0 +1,75 @@
+package gotry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregatedErrorsHoldEveryAttempt(t *testing.T) {
+	attemptErrors := []error{errors.New("first"), errors.New("second"), ExpectedError}
+	invoked := 0
+	err := NewPolicy().WithRetryLimit(2).WithAggregatedErrors().TryMethod(func() error {
+		invoked++
+		return attemptErrors[invoked-1]
+	})
+	var retryError *RetryError
+	assert.True(t, errors.As(err, &retryError))
+	assert.Equal(t, ExpectedError, retryError.LastError)
+	assert.Equal(t, 3, len(retryError.Attempts))
+	for i, attempt := range retryError.Attempts {
+		assert.Equal(t, i+1, attempt.Attempt)
+		assert.Equal(t, attemptErrors[i], attempt.LastError)
+		assert.True(t, errors.Is(err, attemptErrors[i]))
+	}
+	lines := strings.Split(err.Error(), "\n")
+	assert.Equal(t, 4, len(lines))
+	assert.Equal(t, "failed after 3 attempts: expectedError", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "\tattempt 1 after "))
+	assert.True(t, strings.HasSuffix(lines[1], ": first"))
+}
+
+func TestAggregatedErrorsKeepPanickedAttempt(t *testing.T) {
+	invoked := 0
+	err := NewPolicy().WithRetryLimit(1).WithAggregatedErrors().TryMethod(func() error {
+		invoked++
+		if invoked == 1 {
+			panic(PanicContent)
+		}
+		return ExpectedError
+	})
+	var retryError *RetryError
+	assert.True(t, errors.As(err, &retryError))
+	assert.Equal(t, 2, len(retryError.Attempts))
+	assert.Equal(t, PanicContent, retryError.Attempts[0].PanicValue)
+	assert.True(t, strings.Contains(err.Error(), "panic: "+PanicContent))
+}
+
+func TestAggregatedErrorsKeepAttemptAbandonedByOverallTimeout(t *testing.T) {
+	var invoked int32
+	funcReturn := NewPolicy().WithRetryLimit(2).WithAggregatedErrors().WithOverallTimeout(timeout).
+		TryFunc(slowThenSuccessFunc(&invoked, 3))
+	var retryError *RetryError
+	assert.True(t, errors.As(funcReturn.Err, &retryError))
+	assert.True(t, errors.Is(funcReturn.Err, TimeoutError))
+	assert.Equal(t, 1, len(retryError.Attempts))
+	assert.Equal(t, TimeoutError, retryError.Attempts[0].LastError)
+}
+
+func TestAggregatedErrorsMatchContextError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	err := NewPolicy().WithRetryForever().WithAggregatedErrors().
+		TryMethodContext(ctx, func(context.Context) error {
+			cancel()
+			return ExpectedError
+		})
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.True(t, errors.Is(err, ExpectedError))
+}
+
+func TestAggregatedErrorsLeaveSuccessAlone(t *testing.T) {
+	assert.Nil(t, NewPolicy().WithRetryLimit(1).WithAggregatedErrors().TryMethod(successMethod))
+}
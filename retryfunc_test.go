@@ -210,26 +210,6 @@ func (suite *RetryFuncTestSuite) TestCancelFuncRetry(){
 	}
 }
 
-func (suite *RetryFuncTestSuite) TestInfiniteRetryFuncWithTimeout(){
-	retried := false
-	suite.policy = suite.policy.WithRetryForever().WithOnFuncRetry(
-		func(retriedCount int, returnValue interface{}, err error){
-			retried = true
-	})
-	errChan := make(chan FuncReturn)
-	go func(){
-		errChan <- suite.policy.WithTimeout(timeout).TryFunc(errorFunc)
-	}()
-	select {
-		case funcReturn := <- errChan: {
-			assert.Equal(suite.T(), TimeoutError, funcReturn.Err)
-			assert.False(suite.T(), funcReturn.Valid)
-			assert.True(suite.T(), retried)
-		}
-		case <- time.After(waitTime): assert.Fail(suite.T(), "timeout")
-	}
-}
-
 func (suite *RetryFuncTestSuite) TestCancelInfiniteRetryFuncWithTimeout(){
 	cancellation := NewCancellation()
 	suite.policy = suite.policy.WithRetryForever().WithOnFuncRetry(
@@ -267,27 +247,10 @@ func (suite *RetryFuncTestSuite) TestRetryFuncWithTimeout(){
 	}
 }
 
-func (suite *RetryFuncTestSuite) TestOnTimeout(){
-	var timeoutReceived time.Duration
-	suite.policy = suite.policy.WithRetryForever().WithTimeout(timeout).WithOnTimeout(func(t time.Duration){
-		timeoutReceived = t
-	})
-	returnChan := make(chan FuncReturn)
-	go func(){
-		returnChan <- suite.policy.TryFunc(errorFunc)
-	}()
-	select {
-	case <- returnChan: {
- 		assert.Equal(suite.T(), timeout, timeoutReceived)
-	}
-	case <- time.After(waitTime): assert.Fail(suite.T(), "timeout")
-	}
-}
-
 func prepareMockOnPanicFuncWithoutOnError() (*mockRetry, func(interface{})){
 	mockRetry := &mockRetry{}
 	onPanicHook := func(panicError interface{}){
-		mockRetry.OnPanic(panicError)
+		mockRetry.OnPanic(panicError.(*PanicError).Value)
 	}
 	mockRetry.On(OnPanicMethodName, PanicContent).Return()
 	return mockRetry, onPanicHook
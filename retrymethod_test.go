@@ -34,6 +34,21 @@ func (suite *RetryMethodTestSuite) TestSuccessMethod(){
 	mockRetry.AssertNotCalled(suite.T(), OnMethodErrorMethodName)
 }
 
+func (suite *RetryMethodTestSuite) TestNewPolicyTriesOnce() {
+	invoked := 0
+	err := NewPolicy().TryMethod(countingMethod(&invoked, ExpectedError))
+	assert.Equal(suite.T(), ExpectedError, err)
+	assert.Equal(suite.T(), 1, invoked)
+
+	invoked = 0
+	funcReturn := NewPolicy().TryFunc(func() FuncReturn {
+		invoked++
+		return successFunc()
+	})
+	assert.Equal(suite.T(), ExpectedReturnValue, funcReturn.ReturnValue)
+	assert.Equal(suite.T(), 1, invoked)
+}
+
 func (suite *RetryMethodTestSuite) TestErrorMethod() {
 
 	var err = suite.policy.TryMethod(errorMethod)
@@ -108,25 +123,6 @@ func (suite *RetryMethodTestSuite) TestCancelMethodRetry(){
 	}
 }
 
-func (suite *RetryMethodTestSuite) TestInfiniteRetryMethodWithTimeout(){
-	retried := false
-	suite.policy = suite.policy.WithRetryForever().WithOnMethodRetry(
-		func(retriedCount int, err error){
-			retried = true
-		})
-	errChan := make(chan error)
-	go func(){
-		errChan <- suite.policy.WithTimeout(timeout).TryMethod(errorMethod)
-	}()
-	select {
-	case err := <- errChan: {
-			assert.Equal(suite.T(), TimeoutError, err)
-			assert.True(suite.T(), retried)
-		}
-		case <- time.After(waitTime): assert.Fail(suite.T(), "timeout")
-	}
-}
-
 func (suite *RetryMethodTestSuite) TestRetryMethodWithTimeout(){
 	suite.policy = suite.policy.WithRetryForever()
 	errorChan := make(chan error)
@@ -144,7 +140,7 @@ func (suite *RetryMethodTestSuite) TestRetryMethodWithTimeout(){
 func prepareMockOnPanicMethodWithoutOnError() (*mockRetry, func(interface{})){
 	mockRetry := &mockRetry{}
 	onPanicHook := func(panicError interface{}){
-		mockRetry.OnPanic(panicError)
+		mockRetry.OnPanic(panicError.(*PanicError).Value)
 	}
 	mockRetry.On(OnPanicMethodName, PanicContent).Return()
 	return mockRetry, onPanicHook
@@ -0,0 +1,134 @@
+package gotry
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// WithRetryOn retries only errors matched by predicate, other errors are returned immediately
+// without firing OnFuncError. Calling it again widens the set of retried errors.
+// Invalid return values with nil error are always retried.
+func (p policy) WithRetryOn(predicate func(err error) bool) Policy {
+	originPredicate := p.retryOn
+	if originPredicate != nil {
+		p.retryOn = func(err error) bool {
+			return originPredicate(err) || predicate(err)
+		}
+	} else {
+		p.retryOn = predicate
+	}
+	return &p
+}
+
+// WithRetryOnErrors retries only errors matching one of targets by errors.Is.
+func (p policy) WithRetryOnErrors(targets ...error) Policy {
+	return p.WithRetryOn(isAnyOf(targets))
+}
+
+// WithRetryOnErrorType retries only errors matching target by errors.As, target is a non-nil pointer
+// to an error type or interface, e.g. new(*net.OpError) or new(net.Error).
+func (p policy) WithRetryOnErrorType(target interface{}) Policy {
+	return p.WithRetryOn(isTypeOf(target))
+}
+
+// WithAbortOn returns errors matched by predicate immediately without firing OnFuncError,
+// even if they are matched by WithRetryOn. Calling it again widens the set of aborted errors.
+func (p policy) WithAbortOn(predicate func(err error) bool) Policy {
+	originPredicate := p.abortOn
+	if originPredicate != nil {
+		p.abortOn = func(err error) bool {
+			return originPredicate(err) || predicate(err)
+		}
+	} else {
+		p.abortOn = predicate
+	}
+	return &p
+}
+
+// WithAbortOnErrors aborts on errors matching one of targets by errors.Is.
+func (p policy) WithAbortOnErrors(targets ...error) Policy {
+	return p.WithAbortOn(isAnyOf(targets))
+}
+
+// WithRetryWhen retries an attempt returning nil error when predicate holds for its return, e.g. a
+// "pending" job state, instead of looking at Valid. Calling it again widens the set of retried returns.
+func (p policy) WithRetryWhen(predicate func(funcReturn FuncReturn) bool) Policy {
+	originPredicate := p.retryWhen
+	if originPredicate != nil {
+		p.retryWhen = func(funcReturn FuncReturn) bool {
+			return originPredicate(funcReturn) || predicate(funcReturn)
+		}
+	} else {
+		p.retryWhen = predicate
+	}
+	return &p
+}
+
+// WithSuccessWhen accepts an attempt returning nil error only when predicate holds for its return,
+// e.g. a status code below 500, instead of looking at Valid. Calling it again narrows the set of
+// accepted returns. It works together with WithRetryWhen, a return has to pass both.
+func (p policy) WithSuccessWhen(predicate func(funcReturn FuncReturn) bool) Policy {
+	originPredicate := p.successWhen
+	if originPredicate != nil {
+		p.successWhen = func(funcReturn FuncReturn) bool {
+			return originPredicate(funcReturn) && predicate(funcReturn)
+		}
+	} else {
+		p.successWhen = predicate
+	}
+	return &p
+}
+
+// judgingReturn sets Valid of every return of funcBody by WithRetryWhen and WithSuccessWhen, so the
+// rest of the policy, and the caller, see the verdict.
+func (p *policy) judgingReturn(funcBody FuncWithContext) FuncWithContext {
+	if p.retryWhen == nil && p.successWhen == nil {
+		return funcBody
+	}
+	return func(ctx context.Context) FuncReturn {
+		funcReturn := funcBody(ctx)
+		if funcReturn.Err != nil {
+			return funcReturn
+		}
+		funcReturn.Valid = (p.successWhen == nil || p.successWhen(funcReturn)) &&
+			(p.retryWhen == nil || !p.retryWhen(funcReturn))
+		return funcReturn
+	}
+}
+
+func (p *policy) isRetryable(err error) bool {
+	if err == nil {
+		return true
+	}
+	if p.abortOn != nil && p.abortOn(err) {
+		return false
+	}
+	return p.retryOn == nil || p.retryOn(err)
+}
+
+func isAnyOf(targets []error) func(error) bool {
+	targets = append([]error(nil), targets...)
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func isTypeOf(target interface{}) func(error) bool {
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Ptr || reflect.ValueOf(target).IsNil() {
+		panic("gotry: error type target must be a non-nil pointer")
+	}
+	if elemType := targetType.Elem(); elemType.Kind() != reflect.Interface &&
+		!elemType.Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		panic("gotry: error type target must point to an interface or a type implementing error")
+	}
+	return func(err error) bool {
+		return errors.As(err, reflect.New(targetType.Elem()).Interface())
+	}
+}
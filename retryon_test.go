@@ -0,0 +1,154 @@
+package gotry
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var NonTransientError = errors.New("nonTransientError")
+
+type transientError struct {
+	reason string
+}
+
+func (e *transientError) Error() string {
+	return e.reason
+}
+
+func countingMethod(invoked *int, err error) Method {
+	return func() error {
+		*invoked++
+		return err
+	}
+}
+
+func TestRetryOnPredicate(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(2).WithRetryOn(func(err error) bool {
+		return err == ExpectedError
+	})
+	assert.Equal(t, ExpectedError, policy.TryMethod(countingMethod(&invoked, ExpectedError)))
+	assert.Equal(t, 3, invoked)
+	invoked = 0
+	assert.Equal(t, NonTransientError, policy.TryMethod(countingMethod(&invoked, NonTransientError)))
+	assert.Equal(t, 1, invoked)
+}
+
+func TestRetryOnErrorsMatchesWrappedError(t *testing.T) {
+	invoked := 0
+	wrapped := fmt.Errorf("wrapped: %w", ExpectedError)
+	policy := NewPolicy().WithRetryLimit(1).WithRetryOnErrors(NonTransientError, ExpectedError)
+	assert.Equal(t, wrapped, policy.TryMethod(countingMethod(&invoked, wrapped)))
+	assert.Equal(t, 2, invoked)
+}
+
+func TestMultipleRetryOnWidenRetriedErrors(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithRetryOnErrors(ExpectedError).WithRetryOnErrors(NonTransientError)
+	_ = policy.TryMethod(countingMethod(&invoked, NonTransientError))
+	assert.Equal(t, 2, invoked)
+}
+
+func TestRetryOnErrorType(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithRetryOnErrorType(new(*transientError))
+	_ = policy.TryMethod(countingMethod(&invoked, fmt.Errorf("wrapped: %w", &transientError{"busy"})))
+	assert.Equal(t, 2, invoked)
+	invoked = 0
+	_ = policy.TryMethod(countingMethod(&invoked, ExpectedError))
+	assert.Equal(t, 1, invoked)
+}
+
+func TestRetryOnErrorTypeRejectsInvalidTarget(t *testing.T) {
+	assert.Panics(t, func() {
+		NewPolicy().WithRetryOnErrorType(transientError{})
+	})
+	assert.Panics(t, func() {
+		NewPolicy().WithRetryOnErrorType(new(string))
+	})
+}
+
+func TestAbortOnSkipsOnError(t *testing.T) {
+	mockRetry, onRetryHook := prepareMockExpectingMethodRetry(1)
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithOnMethodRetry(onRetryHook).WithAbortOnErrors(ExpectedError)
+	assert.Equal(t, ExpectedError, policy.TryMethod(countingMethod(&invoked, ExpectedError)))
+	assert.Equal(t, 1, invoked)
+	mockRetry.AssertNotCalled(t, OnMethodErrorMethodName, 0, ExpectedError)
+}
+
+func TestAbortOnOverridesRetryOn(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithRetryOnErrors(ExpectedError).WithAbortOn(func(err error) bool {
+		return errors.Is(err, ExpectedError)
+	})
+	_ = policy.TryMethod(countingMethod(&invoked, ExpectedError))
+	assert.Equal(t, 1, invoked)
+}
+
+func TestInvalidReturnIsRetriedRegardlessOfRetryOn(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithRetryOnErrors(ExpectedError)
+	funcReturn := policy.TryFunc(func() FuncReturn {
+		invoked++
+		return FuncReturn{ExpectedReturnValue, false, nil}
+	})
+	assert.False(t, funcReturn.Valid)
+	assert.Equal(t, 2, invoked)
+}
+
+func statusFunc(invoked *int, statuses ...int) Func {
+	return func() FuncReturn {
+		*invoked++
+		return FuncReturn{ReturnValue: statuses[*invoked-1]}
+	}
+}
+
+func TestRetryWhenDecidesFromReturnValue(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(3).WithRetryWhen(func(funcReturn FuncReturn) bool {
+		return funcReturn.ReturnValue.(int) >= 500
+	})
+	funcReturn := policy.TryFunc(statusFunc(&invoked, 503, 502, 200))
+	assert.Equal(t, 3, invoked)
+	assert.True(t, funcReturn.Valid)
+	assert.Equal(t, 200, funcReturn.ReturnValue)
+}
+
+func TestSuccessWhenDecidesFromReturnValue(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithSuccessWhen(func(funcReturn FuncReturn) bool {
+		return funcReturn.ReturnValue.(int) < 500
+	})
+	funcReturn := policy.TryFunc(statusFunc(&invoked, 503, 504))
+	assert.Equal(t, 2, invoked)
+	assert.False(t, funcReturn.Valid)
+	assert.Equal(t, 504, funcReturn.ReturnValue)
+}
+
+func TestRetryWhenAndSuccessWhenCombine(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(3).
+		WithSuccessWhen(func(funcReturn FuncReturn) bool {
+			return funcReturn.ReturnValue.(int) < 500
+		}).
+		WithRetryWhen(func(funcReturn FuncReturn) bool {
+			return funcReturn.ReturnValue.(int) == 429
+		})
+	funcReturn := policy.TryFunc(statusFunc(&invoked, 500, 429, 404))
+	assert.Equal(t, 3, invoked)
+	assert.True(t, funcReturn.Valid)
+	assert.Equal(t, 404, funcReturn.ReturnValue)
+}
+
+func TestRetryWhenLeavesErrorsToRetryOn(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithAbortOnErrors(ExpectedError).
+		WithRetryWhen(func(FuncReturn) bool {
+			return true
+		})
+	assert.Equal(t, ExpectedError, policy.TryMethod(countingMethod(&invoked, ExpectedError)))
+	assert.Equal(t, 1, invoked)
+}
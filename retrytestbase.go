@@ -9,10 +9,11 @@ import (
 
 const ExpectedReturnValue = 1
 const (
-	OnPanicMethodName       = "OnPanic"
-	OnMethodErrorMethodName = "OnMethodError"
-	OnFuncErrorMethodName   = "OnFuncError"
-	OnTimeoutMethodName     = "OnTimeout"
+	OnPanicMethodName          = "OnPanic"
+	OnMethodErrorMethodName    = "OnMethodError"
+	OnFuncErrorMethodName      = "OnFuncError"
+	OnTimeoutMethodName        = "OnTimeout"
+	OnAttemptTimeoutMethodName = "OnAttemptTimeout"
 )
 var ExpectedError = errors.New("expectedError")
 const PanicContent ="test panic"
@@ -51,4 +52,7 @@ func (hook *mockRetry) OnPanic(panicError interface{}){
 }
 func (hook *mockRetry) OnTimeout(duration time.Duration) {
 	hook.Called(duration)
+}
+func (hook *mockRetry) OnAttemptTimeout(retryAttempt int, duration time.Duration) {
+	hook.Called(retryAttempt, duration)
 }
\ No newline at end of file
@@ -0,0 +1,88 @@
+package gotry_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lonegunmanb/gotry"
+	"github.com/lonegunmanb/gotry/gotrytest"
+	"github.com/stretchr/testify/assert"
+)
+
+// These tests run overall timeout on a FakeClock, so the timeout expires only once the retry loop is
+// waiting for its backoff, whatever the load of the machine.
+
+const overallTimeout = time.Minute
+
+var start = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+var errExpected = errors.New("expected")
+
+// retryingForever retries every second until overall timeout expires.
+func retryingForever(clock *gotrytest.FakeClock) gotry.Policy {
+	return gotry.NewPolicy().
+		WithRetryForever().
+		WithBackoff(gotry.NewConstantBackoff(time.Second)).
+		WithTimeout(overallTimeout).
+		WithClock(clock)
+}
+
+// expireTimeout waits until both overall timeout and the first backoff are waiting, then expires the
+// timeout.
+func expireTimeout(clock *gotrytest.FakeClock) {
+	clock.BlockUntil(2)
+	clock.Advance(overallTimeout)
+}
+
+func TestInfiniteRetryFuncWithTimeout(t *testing.T) {
+	clock := gotrytest.NewFakeClock(start)
+	retried := false
+	policy := retryingForever(clock).WithOnFuncRetry(func(int, interface{}, error) {
+		retried = true
+	})
+	returnChan := make(chan gotry.FuncReturn, 1)
+	go func() {
+		returnChan <- policy.TryFunc(func() gotry.FuncReturn {
+			return gotry.FuncReturn{Valid: false, Err: errExpected}
+		})
+	}()
+	expireTimeout(clock)
+	funcReturn := <-returnChan
+	assert.Equal(t, gotry.TimeoutError, funcReturn.Err)
+	assert.False(t, funcReturn.Valid)
+	assert.True(t, retried)
+}
+
+func TestInfiniteRetryMethodWithTimeout(t *testing.T) {
+	clock := gotrytest.NewFakeClock(start)
+	retried := false
+	policy := retryingForever(clock).WithOnMethodRetry(func(int, error) {
+		retried = true
+	})
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- policy.TryMethod(func() error {
+			return errExpected
+		})
+	}()
+	expireTimeout(clock)
+	assert.Equal(t, gotry.TimeoutError, <-errChan)
+	assert.True(t, retried)
+}
+
+func TestOnTimeout(t *testing.T) {
+	clock := gotrytest.NewFakeClock(start)
+	var timeoutReceived time.Duration
+	policy := retryingForever(clock).WithOnTimeout(func(timeout time.Duration) {
+		timeoutReceived = timeout
+	})
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- policy.TryMethod(func() error {
+			return errExpected
+		})
+	}()
+	expireTimeout(clock)
+	assert.Equal(t, gotry.TimeoutError, <-errChan)
+	assert.Equal(t, overallTimeout, timeoutReceived)
+}
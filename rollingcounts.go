@@ -0,0 +1,46 @@
+package gotry
+
+import "time"
+
+const bucketsPerWindow = 10
+
+// rollingCounts keeps a pair of counters over a sliding window. The window is split into buckets,
+// so old counts expire in slices instead of being remembered one by one.
+type rollingCounts struct {
+	buckets []countBucket
+}
+
+type countBucket struct {
+	start  time.Time
+	counts [2]int
+}
+
+func (r *rollingCounts) add(now time.Time, window time.Duration, counter int) {
+	r.expire(now, window)
+	bucketDuration := window / bucketsPerWindow
+	if len(r.buckets) == 0 || now.Sub(r.buckets[len(r.buckets)-1].start) >= bucketDuration {
+		r.buckets = append(r.buckets, countBucket{start: now})
+	}
+	r.buckets[len(r.buckets)-1].counts[counter]++
+}
+
+func (r *rollingCounts) sum(now time.Time, window time.Duration) (first int, second int) {
+	r.expire(now, window)
+	for _, bucket := range r.buckets {
+		first += bucket.counts[0]
+		second += bucket.counts[1]
+	}
+	return
+}
+
+func (r *rollingCounts) expire(now time.Time, window time.Duration) {
+	expired := 0
+	for expired < len(r.buckets) && now.Sub(r.buckets[expired].start) >= window {
+		expired++
+	}
+	r.buckets = r.buckets[expired:]
+}
+
+func (r *rollingCounts) reset() {
+	r.buckets = nil
+}
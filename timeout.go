@@ -0,0 +1,195 @@
+package gotry
+
+import (
+	"sync"
+	"time"
+)
+
+type TimeoutStrategy int
+
+const (
+	// TimeoutPessimistic runs body in its own goroutine and stops waiting for it once timeout expires.
+	// The abandoned body is still signalled through its context, and OnAbandoned reports its result
+	// when it finally returns.
+	TimeoutPessimistic TimeoutStrategy = iota
+	// TimeoutOptimistic cancels the context passed to body once timeout expires and waits for body
+	// to return, body runs in the caller's goroutine. No goroutine is started with SystemClock,
+	// another Clock costs one waiting for its timer. Body that ignores its context runs to the end.
+	TimeoutOptimistic
+)
+
+// WithTimeout is the same as WithOverallTimeout.
+func (p policy) WithTimeout(timeout time.Duration) Policy{
+	return p.WithOverallTimeout(timeout)
+}
+
+// WithOverallTimeout limits the whole execution, retries and backoff included.
+func (p policy) WithOverallTimeout(timeout time.Duration) Policy{
+	p.timeout = &timeout
+	p.funcExecutor = func(policy *policy, funcBody FuncWithContext) FuncReturn {
+		return policy.tryFuncWithTimeout(funcBody, timeout)
+	}
+	return &p
+}
+
+// WithAttemptTimeout limits every single attempt. An attempt exceeding it returns TimeoutError,
+// which is retried like any other error within the remaining retries and overall timeout.
+func (p policy) WithAttemptTimeout(timeout time.Duration) Policy{
+	p.attemptTimeout = &timeout
+	return &p
+}
+
+// WithTimeoutStrategy decides how both overall and attempt timeout stop body, TimeoutPessimistic by default.
+func (p policy) WithTimeoutStrategy(strategy TimeoutStrategy) Policy{
+	p.timeoutStrategy = strategy
+	return &p
+}
+
+func (p *policy) tryFuncWithTimeout(funcBody FuncWithContext, duration time.Duration) FuncReturn {
+	tracked := *p
+	if tracked.progress == nil {
+		tracked.progress = newAttemptProgress(p.clock)
+	}
+	result, timedOut := p.runWithTimeout(func(timeoutCancellation *cancellation) FuncReturn {
+		return tracked.tryFuncWithCancellation(funcBody, timeoutCancellation, directTryFunc)
+	}, duration)
+	if timedOut {
+		notifyOnTimeout(p, duration)
+		p.notifyOnTimeoutEx(tracked.progress.timedOut())
+		return FuncReturn{Valid: false, Err: TimeoutError}
+	}
+	return result.unwrap()
+}
+
+func (p *policy) withAttemptTimeout(funcBody FuncWithContext, retried int, attempt *AttemptInfo) Func {
+	if p.attemptTimeout == nil {
+		return func() FuncReturn {
+			return funcBody(contextOf(p.cancellation))
+		}
+	}
+	timeout := *p.attemptTimeout
+	return func() FuncReturn {
+		result, timedOut := p.runWithTimeout(func(attemptCancellation *cancellation) FuncReturn {
+			return funcBody(attemptCancellation.context())
+		}, timeout)
+		if timedOut {
+			p.finishAttempt(attempt, FuncReturn{Valid: false, Err: TimeoutError})
+			p.progress.fire(func() {
+				notifyOnTimeout(p, timeout)
+				p.notifyOnAttemptTimeout(retried, timeout)
+				p.notifyOnAttemptTimeoutEx(*attempt)
+			})
+			return FuncReturn{Valid: false, Err: TimeoutError}
+		}
+		return result.unwrapAttempt()
+	}
+}
+
+// runWithTimeout runs body with a cancellation which is cancelled once timeout expires or the
+// policy's own cancellation is cancelled, and reports whether body failed to finish in time.
+func (p *policy) runWithTimeout(body func(*cancellation) FuncReturn, timeout time.Duration) (bodyResult, bool) {
+	timeoutCancellation := newLinkedCancellation(p.cancellation)
+	// signals body to stop, and unlinks timeoutCancellation from the policy's cancellation
+	defer timeoutCancellation.Cancel()
+	if p.timeoutStrategy == TimeoutOptimistic {
+		return runOptimistic(p.clock, body, timeoutCancellation, timeout)
+	}
+	return p.runPessimistic(body, timeoutCancellation, timeout)
+}
+
+func runOptimistic(clock Clock, body func(*cancellation) FuncReturn, timeoutCancellation *cancellation, timeout time.Duration) (bodyResult, bool) {
+	stop := afterFunc(clock, timeout, func() {
+		timeoutCancellation.Cancel()
+	})
+	defer stop()
+	funcReturn := body(timeoutCancellation)
+	expired := !stop()
+	return bodyResult{funcReturn: funcReturn}, expired && !success(funcReturn)
+}
+
+// bodyResult is what body run in a goroutine of its own left, a panic is recovered as *PanicError in
+// funcReturn while the stack still shows where body panicked.
+type bodyResult struct {
+	funcReturn FuncReturn
+	panicked   bool
+	panicErr   interface{}
+}
+
+// runPessimistic never blocks body's goroutine: the result is either buffered for us, or handed to
+// OnAbandoned when we have stopped waiting. A panic before timeout is raised again in the caller's
+// goroutine so it is handled like any other attempt.
+func (p *policy) runPessimistic(body func(*cancellation) FuncReturn, timeoutCancellation *cancellation, timeout time.Duration) (bodyResult, bool) {
+	var mutex sync.Mutex
+	abandoned := false
+	resultChan := make(chan bodyResult, 1)
+	go func() {
+		result := bodyResult{panicked: true}
+		defer func() {
+			var panicError interface{}
+			if result.panicked {
+				result.panicErr = recover()
+				recovered := newPanicError(result.panicErr)
+				result.funcReturn = FuncReturn{Valid: false, Err: recovered}
+				panicError = recovered
+			}
+			mutex.Lock()
+			late := abandoned
+			if !late {
+				resultChan <- result
+			}
+			mutex.Unlock()
+			if late {
+				p.notifyOnAbandoned(result.funcReturn, panicError)
+			}
+		}()
+		result.funcReturn = body(timeoutCancellation)
+		result.panicked = false
+	}()
+	timer := p.clock.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case result := <-resultChan:
+		return result, false
+	case <-timer.C():
+		mutex.Lock()
+		abandoned = true
+		mutex.Unlock()
+		// body may have returned right before we gave up
+		select {
+		case result := <-resultChan:
+			return result, false
+		default:
+			return bodyResult{}, true
+		}
+	}
+}
+
+// unwrap raises a panic of body again with its own value, so it leaves the policy unchanged.
+func (r bodyResult) unwrap() FuncReturn {
+	if r.panicked {
+		panic(r.panicErr)
+	}
+	return r.funcReturn
+}
+
+// attemptPanic carries the PanicError of an attempt run in a goroutine of its own to the attempt loop,
+// which reports it instead of a PanicError whose stack no longer shows the body.
+type attemptPanic struct {
+	panicError *PanicError
+}
+
+func (r bodyResult) unwrapAttempt() FuncReturn {
+	if r.panicked {
+		panic(attemptPanic{panicError: r.funcReturn.Err.(*PanicError)})
+	}
+	return r.funcReturn
+}
+
+// recoverPanicError describes a panic recovered from an attempt, with the stack where it was raised.
+// It must be called by the deferred function recovering value, like newPanicError.
+func recoverPanicError(value interface{}) *PanicError {
+	if carried, ok := value.(attemptPanic); ok {
+		return carried.panicError
+	}
+	return newPanicError(value)
+}
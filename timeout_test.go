@@ -0,0 +1,151 @@
+package gotry
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func assertNoGoroutineLeak(t *testing.T, baseline int) {
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > baseline && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), baseline, "goroutine leaked")
+}
+
+func TestPessimisticTimeoutReportsAbandonedResult(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	release := make(chan struct{})
+	abandoned := make(chan FuncReturn, 1)
+	policy := NewPolicy().WithTimeout(timeout).WithOnAbandoned(func(lateReturn FuncReturn, panicError interface{}) {
+		assert.Nil(t, panicError)
+		abandoned <- lateReturn
+	})
+	funcReturn := policy.TryFunc(func() FuncReturn {
+		<-release
+		return successFunc()
+	})
+	assert.Equal(t, TimeoutError, funcReturn.Err)
+	close(release)
+	select {
+	case lateReturn := <-abandoned:
+		assert.Equal(t, ExpectedReturnValue, lateReturn.ReturnValue)
+	case <-time.After(time.Second):
+		assert.Fail(t, "abandoned result should be reported")
+	}
+	assertNoGoroutineLeak(t, baseline)
+}
+
+func TestPessimisticTimeoutReportsAbandonedPanic(t *testing.T) {
+	release := make(chan struct{})
+	abandoned := make(chan interface{}, 1)
+	policy := NewPolicy().WithAttemptTimeout(timeout).WithLetItPanic().
+		WithOnAbandoned(func(lateReturn FuncReturn, panicError interface{}) {
+			abandoned <- panicError
+		})
+	err := policy.TryMethod(func() error {
+		<-release
+		panic(PanicContent)
+	})
+	assert.Equal(t, TimeoutError, err)
+	close(release)
+	select {
+	case panicError := <-abandoned:
+		assert.Equal(t, PanicContent, panicError.(*PanicError).Value)
+		assert.True(t, strings.Contains(string(panicError.(*PanicError).Stack), "timeout_test.go"))
+	case <-time.After(time.Second):
+		assert.Fail(t, "abandoned panic should be reported")
+	}
+}
+
+func TestPessimisticTimeoutSignalsAbandonedBody(t *testing.T) {
+	bodyCancelled := make(chan struct{})
+	err := NewPolicy().WithAttemptTimeout(timeout).TryMethodContext(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		close(bodyCancelled)
+		return ctx.Err()
+	})
+	assert.Equal(t, TimeoutError, err)
+	select {
+	case <-bodyCancelled:
+	case <-time.After(time.Second):
+		assert.Fail(t, "abandoned body should be signalled")
+	}
+}
+
+func TestOptimisticOverallTimeoutCancelsBody(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	timedOut := false
+	policy := NewPolicy().WithRetryForever().WithTimeout(timeout).WithTimeoutStrategy(TimeoutOptimistic).
+		WithOnTimeout(func(time.Duration) {
+			timedOut = true
+		})
+	err := policy.TryMethodContext(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ExpectedError
+	})
+	assert.Equal(t, TimeoutError, err)
+	assert.True(t, timedOut)
+	assertNoGoroutineLeak(t, baseline)
+}
+
+func TestOptimisticAttemptTimeoutIsRetried(t *testing.T) {
+	invoked := 0
+	policy := NewPolicy().WithRetryLimit(1).WithAttemptTimeout(timeout).WithTimeoutStrategy(TimeoutOptimistic)
+	err := policy.TryMethodContext(context.Background(), func(ctx context.Context) error {
+		invoked++
+		if invoked == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, invoked)
+}
+
+func TestOptimisticTimeoutKeepsLateSuccess(t *testing.T) {
+	policy := NewPolicy().WithTimeout(timeout).WithTimeoutStrategy(TimeoutOptimistic)
+	funcReturn := policy.TryFunc(func() FuncReturn {
+		time.Sleep(waitTime)
+		return successFunc()
+	})
+	assert.Nil(t, funcReturn.Err)
+	assert.Equal(t, ExpectedReturnValue, funcReturn.ReturnValue)
+}
+
+func TestOverallTimeoutPanicRaisedInCaller(t *testing.T) {
+	policy := NewPolicy().WithTimeout(time.Second).WithLetItPanic()
+	assert.PanicsWithValue(t, PanicContent, func() {
+		policy.TryFunc(panicFunc)
+	})
+}
+
+func TestOverallTimeoutSilencesAbandonedRetries(t *testing.T) {
+	// plain counters: under -race any event fired after TryMethod returns is reported
+	retries, retriesEx, attemptsDone := 0, 0, 0
+	policy := NewPolicy().WithRetryForever().WithTimeout(timeout).
+		WithOnMethodRetry(func(int, error) {
+			retries++
+		}).
+		WithOnRetryEx(func(AttemptInfo) {
+			retriesEx++
+		}).
+		WithOnAttemptDone(func(AttemptInfo) {
+			attemptsDone++
+		})
+	err := policy.TryMethod(func() error {
+		time.Sleep(time.Millisecond)
+		return ExpectedError
+	})
+	assert.Equal(t, TimeoutError, err)
+	fired := []int{retries, retriesEx, attemptsDone}
+	assert.NotEqual(t, 0, fired[0])
+	time.Sleep(5 * time.Millisecond)
+	assert.Equal(t, fired, []int{retries, retriesEx, attemptsDone})
+}
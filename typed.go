@@ -0,0 +1,90 @@
+package gotry
+
+import (
+	"context"
+	"errors"
+)
+
+type OnTypedRetry[T any] func(retriedCount int, returnValue T, err error)
+
+// TypedPolicy is a type-safe view over Policy, so callers need no type assertion on ReturnValue.
+type TypedPolicy[T any] interface {
+	WithOnRetry(onRetry OnTypedRetry[T]) TypedPolicy[T]
+	WithValidWhen(isValid func(returnValue T) bool) TypedPolicy[T]
+	TryFunc(funcBody func() (T, error)) (T, error)
+	TryFuncContext(ctx context.Context, funcBody func(ctx context.Context) (T, error)) (T, error)
+	Policy() Policy
+}
+
+type typedPolicy[T any] struct {
+	policy  Policy
+	isValid func(T) bool
+}
+
+// InvalidReturnError is returned by the typed API when retries are exhausted and the last
+// return value was rejected by the validity predicate.
+var InvalidReturnError = errors.New("invalid return value")
+
+func NewTypedPolicy[T any](policy Policy) TypedPolicy[T] {
+	return &typedPolicy[T]{
+		policy:  policy,
+		isValid: func(T) bool { return true },
+	}
+}
+
+// Try runs funcBody with policy and returns its typed result.
+func Try[T any](policy Policy, funcBody func() (T, error)) (T, error) {
+	return NewTypedPolicy[T](policy).TryFunc(funcBody)
+}
+
+// TryContext runs funcBody with policy until ctx is done and returns its typed result.
+func TryContext[T any](ctx context.Context, policy Policy, funcBody func(ctx context.Context) (T, error)) (T, error) {
+	return NewTypedPolicy[T](policy).TryFuncContext(ctx, funcBody)
+}
+
+func (p typedPolicy[T]) WithOnRetry(onRetry OnTypedRetry[T]) TypedPolicy[T] {
+	p.policy = p.policy.WithOnFuncRetry(func(retriedCount int, returnValue interface{}, err error) {
+		onRetry(retriedCount, typedValue[T](returnValue), err)
+	})
+	return &p
+}
+
+func (p typedPolicy[T]) WithValidWhen(isValid func(returnValue T) bool) TypedPolicy[T] {
+	p.isValid = isValid
+	return &p
+}
+
+func (p *typedPolicy[T]) TryFunc(funcBody func() (T, error)) (T, error) {
+	return p.typedReturn(p.policy.TryFunc(func() FuncReturn {
+		return p.untypedReturn(funcBody())
+	}))
+}
+
+func (p *typedPolicy[T]) TryFuncContext(ctx context.Context, funcBody func(ctx context.Context) (T, error)) (T, error) {
+	return p.typedReturn(p.policy.TryFuncContext(ctx, func(ctx context.Context) FuncReturn {
+		return p.untypedReturn(funcBody(ctx))
+	}))
+}
+
+func (p *typedPolicy[T]) Policy() Policy {
+	return p.policy
+}
+
+func (p *typedPolicy[T]) untypedReturn(returnValue T, err error) FuncReturn {
+	// validity is only judged for calls that did not fail
+	valid := err != nil || p.isValid(returnValue)
+	return FuncReturn{ReturnValue: returnValue, Valid: valid, Err: err}
+}
+
+func (p *typedPolicy[T]) typedReturn(funcReturn FuncReturn) (T, error) {
+	if funcReturn.Err == nil && !funcReturn.Valid {
+		return typedValue[T](funcReturn.ReturnValue), InvalidReturnError
+	}
+	return typedValue[T](funcReturn.ReturnValue), funcReturn.Err
+}
+
+// typedValue returns the zero value of T when returnValue is nil, e.g. when the attempt timed out.
+func typedValue[T any](returnValue interface{}) T {
+	value, _ := returnValue.(T)
+	return value
+}
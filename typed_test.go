@@ -0,0 +1,78 @@
+package gotry
+
+import (
+	"context"
+	"testing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrySuccess(t *testing.T) {
+	value, err := Try(NewPolicy().WithRetryLimit(1), func() (int, error) {
+		return ExpectedReturnValue, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, ExpectedReturnValue, value)
+}
+
+func TestTryError(t *testing.T) {
+	invoked := 0
+	value, err := Try(NewPolicy().WithRetryLimit(1), func() (string, error) {
+		invoked++
+		return "last", ExpectedError
+	})
+	assert.Equal(t, ExpectedError, err)
+	assert.Equal(t, "last", value)
+	assert.Equal(t, 2, invoked)
+}
+
+func TestTypedOnRetry(t *testing.T) {
+	var retriedValues []int
+	policy := NewTypedPolicy[int](NewPolicy().WithRetryLimit(1)).
+		WithOnRetry(func(retriedCount int, returnValue int, err error) {
+			retriedValues = append(retriedValues, returnValue+retriedCount)
+		})
+	_, err := policy.TryFunc(func() (int, error) {
+		return ExpectedReturnValue, ExpectedError
+	})
+	assert.Equal(t, ExpectedError, err)
+	assert.Equal(t, []int{ExpectedReturnValue, ExpectedReturnValue + 1}, retriedValues)
+}
+
+func TestTypedValidWhen(t *testing.T) {
+	count := 0
+	policy := NewTypedPolicy[int](NewPolicy().WithRetryForever()).WithValidWhen(func(value int) bool {
+		return value > 2
+	})
+	value, err := policy.TryFunc(func() (int, error) {
+		count++
+		return count, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, value)
+}
+
+func TestTypedInvalidReturn(t *testing.T) {
+	policy := NewTypedPolicy[int](NewPolicy().WithRetryLimit(1)).WithValidWhen(func(int) bool {
+		return false
+	})
+	value, err := policy.TryFunc(func() (int, error) {
+		return ExpectedReturnValue, nil
+	})
+	assert.Equal(t, InvalidReturnError, err)
+	assert.Equal(t, ExpectedReturnValue, value)
+}
+
+func TestTryContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	value, err := TryContext(ctx, NewPolicy().WithRetryForever(), func(context.Context) (*int, error) {
+		return new(int), nil
+	})
+	assert.Equal(t, context.Canceled, err)
+	assert.Nil(t, value)
+}
+
+func TestTypedPolicyKeepsClassicPolicy(t *testing.T) {
+	classic := NewPolicy().WithRetryLimit(1)
+	assert.Equal(t, classic, NewTypedPolicy[int](classic).Policy())
+}